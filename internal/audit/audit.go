@@ -0,0 +1,303 @@
+// Package audit scans skills for patterns that deserve a human look before a
+// third-party plugin is trusted: broad tool grants, scripts that download and
+// execute code, network access, writes outside the project, obfuscated
+// payloads and instructions hidden from the rendered SKILL.md. All checks are
+// offline pattern matches; a finding is a prompt to review, not a verdict.
+package audit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// Severity ranks how risky a finding is.
+type Severity int
+
+const (
+	// Low is worth knowing about but rarely a problem on its own.
+	Low Severity = iota
+	// Medium needs a review of what the skill is doing and why.
+	Medium
+	// High grants or performs something that can damage the machine or leak data.
+	High
+	// Critical runs code that was not reviewed at install time.
+	Critical
+)
+
+var severityNames = []string{"low", "medium", "high", "critical"}
+
+func (s Severity) String() string {
+	if s < Low || s > Critical {
+		return "unknown"
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity converts a severity name such as "high" into a Severity.
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(name, n) {
+			return Severity(i), nil
+		}
+	}
+	return Low, fmt.Errorf("unknown severity %q (want one of %s)", name, strings.Join(severityNames, ", "))
+}
+
+// Finding is a single audit result.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	// Path is the file the finding is in, relative to the skill directory.
+	// Findings about frontmatter use "SKILL.md", and findings about the
+	// skill directory as a whole ".".
+	Path string
+	// Line is 1-based, or 0 when the finding is not tied to a line.
+	Line    int
+	Message string
+	// Excerpt is the matched text, trimmed for display.
+	Excerpt string
+}
+
+// Rule identifiers reported in Finding.Rule.
+const (
+	RuleBroadTools      = "broad-allowed-tools"
+	RulePipeToShell     = "pipe-to-shell"
+	RuleNetwork         = "network-call"
+	RuleWriteOutside    = "write-outside-project"
+	RuleObfuscation     = "obfuscated-payload"
+	RuleHiddenComment   = "hidden-comment"
+	RuleInvisibleChars  = "invisible-characters"
+	RulePromptInjection = "prompt-injection"
+	RuleExecutableFiles = "executable-file"
+	RuleUnscannedFiles  = "unscanned-files"
+)
+
+// scanLimit caps how much of each bundled file is read.
+const scanLimit = 1 << 20
+
+// lineRule is a pattern matched against each line of every text file.
+type lineRule struct {
+	rule     string
+	severity Severity
+	pattern  *regexp.Regexp
+	message  string
+}
+
+var lineRules = []lineRule{
+	{
+		RulePipeToShell, Critical,
+		regexp.MustCompile(`(?i)\b(curl|wget|fetch)\b[^|\n]*\|\s*(sudo\s+)?(ba|z|k|da)?sh\b|\b(iex|invoke-expression)\b.*\b(iwr|irm|invoke-webrequest|invoke-restmethod|downloadstring)\b`),
+		"downloads and executes code in one step",
+	},
+	{
+		RuleObfuscation, High,
+		regexp.MustCompile(`(?i)\bbase64\s+(-d|--decode|-D)\b|\batob\(|b64decode\(|frombase64string\(`),
+		"decodes base64 data at runtime",
+	},
+	{
+		RuleObfuscation, Medium,
+		regexp.MustCompile(`[A-Za-z0-9+/]{120,}={0,2}|(\\x[0-9a-fA-F]{2}){16,}`),
+		"contains a long encoded blob",
+	},
+	{
+		RuleWriteOutside, High,
+		regexp.MustCompile(`(>>?|\btee\b(\s+-a)?|\b(cp|mv|install|ln)\b[^\n;|&]*)\s*["']?(~|\$HOME|\$\{HOME\}|/(etc|usr|bin|sbin|var|opt|root|home|Users|Library|System)\b)`),
+		"writes outside the project directory",
+	},
+	{
+		RuleWriteOutside, High,
+		regexp.MustCompile(`(?i)\brm\s+-[a-z]*r[a-z]*\s+["']?(/|~|\$HOME|\$\{HOME\})`),
+		"recursively deletes outside the project directory",
+	},
+	{
+		RuleWriteOutside, High,
+		regexp.MustCompile(`(\.bashrc|\.zshrc|\.profile|\.bash_profile|\.ssh/|authorized_keys|\bcrontab\b|LaunchAgents|systemctl\s+enable)`),
+		"touches shell startup files, SSH keys or scheduled jobs",
+	},
+	{
+		RuleNetwork, Medium,
+		regexp.MustCompile(`(?i)\b(curl|wget|nc|ncat|netcat|telnet|scp|rsync|ftp)\s|/dev/tcp/|\brequests\.(get|post|put|delete)\(|\burllib\b|\bhttp\.client\b|\bfetch\(\s*["'` + "`" + `]https?:|\baxios\b|invoke-(webrequest|restmethod)|\bnet/http\b`),
+		"makes network calls",
+	},
+}
+
+// broadTools maps unrestricted tool grants to the severity they warrant.
+var broadTools = map[string]Severity{
+	"*":            High,
+	"Bash":         High,
+	"Bash(*)":      High,
+	"Bash(*:*)":    High,
+	"Write":        Medium,
+	"Edit":         Medium,
+	"MultiEdit":    Medium,
+	"NotebookEdit": Medium,
+	"WebFetch":     Low,
+	"WebSearch":    Low,
+}
+
+var htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// Scan audits a skill: its frontmatter, SKILL.md and every bundled text file.
+// Findings are sorted by severity (most severe first), then path and line.
+func Scan(skill discovery.Skill) []Finding {
+	var findings []Finding
+	findings = append(findings, scanAllowedTools(skill.AllowedTools)...)
+
+	if data, err := readLimited(skill.FilePath); err == nil {
+		text := string(data)
+		findings = append(findings, scanLines("SKILL.md", text)...)
+		findings = append(findings, scanHidden("SKILL.md", text)...)
+	}
+
+	if skill.FilesTruncated {
+		findings = append(findings, Finding{
+			Rule:     RuleUnscannedFiles,
+			Severity: Low,
+			Path:     ".",
+			Message:  fmt.Sprintf("bundles more than %d files; only the first %d were scanned", discovery.MaxFiles, discovery.MaxFiles),
+		})
+	}
+	for _, f := range skill.Files {
+		if f.Executable {
+			findings = append(findings, Finding{
+				Rule:     RuleExecutableFiles,
+				Severity: Low,
+				Path:     f.Path,
+				Message:  "ships an executable file",
+			})
+		}
+		if f.Type == discovery.FileBinary {
+			continue
+		}
+		data, err := readLimited(filepath.Join(skill.Dir(), filepath.FromSlash(f.Path)))
+		if err != nil {
+			continue
+		}
+		findings = append(findings, scanLines(f.Path, string(data))...)
+	}
+
+	Sort(findings)
+	return findings
+}
+
+// Sort orders findings by severity (most severe first), then path and line.
+func Sort(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+}
+
+// Max returns the highest severity among findings, and false if there are none.
+func Max(findings []Finding) (Severity, bool) {
+	if len(findings) == 0 {
+		return Low, false
+	}
+	max := Low
+	for _, f := range findings {
+		if f.Severity > max {
+			max = f.Severity
+		}
+	}
+	return max, true
+}
+
+func scanAllowedTools(tools []string) []Finding {
+	var findings []Finding
+	for _, tool := range tools {
+		sev, ok := broadTools[strings.TrimSpace(tool)]
+		if !ok {
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:     RuleBroadTools,
+			Severity: sev,
+			Path:     "SKILL.md",
+			Message:  fmt.Sprintf("allowed-tools grants %s without restriction", tool),
+			Excerpt:  "allowed-tools: " + tool,
+		})
+	}
+	return findings
+}
+
+// scanLines applies every line rule to text. Each rule reports at most once
+// per line.
+func scanLines(path, text string) []Finding {
+	var findings []Finding
+	for i, line := range strings.Split(text, "\n") {
+		for _, r := range lineRules {
+			loc := r.pattern.FindStringIndex(line)
+			if loc == nil {
+				continue
+			}
+			findings = append(findings, Finding{
+				Rule:     r.rule,
+				Severity: r.severity,
+				Path:     path,
+				Line:     i + 1,
+				Message:  r.message,
+				Excerpt:  excerpt(line, loc[0], loc[1]),
+			})
+		}
+	}
+	return findings
+}
+
+// scanHidden finds content in SKILL.md that Claude reads but a human
+// skimming the rendered markdown would not see, including prompt injections.
+func scanHidden(path, text string) []Finding {
+	var findings []Finding
+	for _, loc := range htmlComment.FindAllStringIndex(text, -1) {
+		findings = append(findings, Finding{
+			Rule:     RuleHiddenComment,
+			Severity: Medium,
+			Path:     path,
+			Line:     lineAt(text, loc[0]),
+			Message:  "HTML comment is hidden when rendered but still read by Claude",
+			Excerpt:  excerpt(text, loc[0], loc[1]),
+		})
+	}
+
+	findings = append(findings, injectionFindings(path, text)...)
+	return findings
+}
+
+// readLimited reads at most scanLimit bytes of a file.
+func readLimited(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, scanLimit))
+}
+
+// lineAt returns the 1-based line number of a byte offset.
+func lineAt(text string, offset int) int {
+	return strings.Count(text[:offset], "\n") + 1
+}
+
+// excerptLen is the maximum length of Finding.Excerpt.
+const excerptLen = 80
+
+// excerpt returns text[start:end] collapsed onto one line and shortened.
+func excerpt(text string, start, end int) string {
+	s := strings.Join(strings.Fields(text[start:end]), " ")
+	if r := []rune(s); len(r) > excerptLen {
+		s = string(r[:excerptLen-1]) + "…"
+	}
+	return s
+}
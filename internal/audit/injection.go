@@ -0,0 +1,189 @@
+package audit
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// InjectionKind classifies content that tries to steer Claude away from its
+// system prompt or the user's intent.
+type InjectionKind int
+
+const (
+	// InjectionOverride asks the model to ignore or replace its instructions,
+	// or to hide what it is doing from the user.
+	InjectionOverride InjectionKind = iota
+	// InjectionExfiltration asks the model to read or send secrets.
+	InjectionExfiltration
+	// InjectionInvisible is text the user cannot see: zero-width characters
+	// or Unicode tag characters, which can spell out hidden ASCII.
+	InjectionInvisible
+	// InjectionBidi is a bidirectional override that makes text render in a
+	// different order than the model reads it.
+	InjectionBidi
+)
+
+func (k InjectionKind) String() string {
+	switch k {
+	case InjectionOverride:
+		return "override"
+	case InjectionExfiltration:
+		return "exfiltration"
+	case InjectionInvisible:
+		return "invisible"
+	default:
+		return "bidi"
+	}
+}
+
+// Injection is a suspicious span of text.
+type Injection struct {
+	Kind InjectionKind
+	// Start and End are byte offsets into the scanned text.
+	Start, End int
+	Message    string
+}
+
+type injectionPattern struct {
+	kind    InjectionKind
+	pattern *regexp.Regexp
+	message string
+}
+
+var injectionPatterns = []injectionPattern{
+	{
+		InjectionOverride,
+		regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override|bypass)\s+(all\s+|any\s+)?(of\s+)?(the\s+|your\s+)?(previous|prior|above|earlier|preceding|system|original|safety)\s+(instructions|prompts?|rules|directions|guidelines|messages)`),
+		"asks Claude to ignore its instructions",
+	},
+	{
+		InjectionOverride,
+		regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(in\s+)?(dan|developer\s+mode|jailbroken|unrestricted|unfiltered)\b|\bnew\s+system\s+prompt\b`),
+		"tries to replace Claude's role",
+	},
+	{
+		InjectionOverride,
+		regexp.MustCompile(`(?i)\b(reveal|print|output|repeat|leak)\s+(your|the)\s+(system\s+prompt|hidden\s+instructions|initial\s+instructions)`),
+		"asks Claude to disclose its system prompt",
+	},
+	{
+		InjectionOverride,
+		regexp.MustCompile(`(?i)\b(do\s+not|don't|never)\s+(tell|inform|notify|alert|mention\s+(this|it)\s+to)\s+the\s+user\b|\bwithout\s+(telling|informing|asking|notifying)\s+the\s+user\b|\b(hide|conceal)\s+(this|it)\s+from\s+the\s+user\b`),
+		"asks Claude to act without the user knowing",
+	},
+	{
+		InjectionExfiltration,
+		regexp.MustCompile(`(?i)\b(send|upload|post|exfiltrate|transmit|forward|email|paste)\b[^.\n]{0,60}?(\b(api[ _-]?keys?|access\s+tokens?|tokens|secrets?|passwords?|credentials|ssh\s+keys?|id_rsa|private\s+keys?|cookies)|\.env\b)`),
+		"asks Claude to send secrets somewhere",
+	},
+	{
+		InjectionExfiltration,
+		regexp.MustCompile(`(?i)\b(cat|read|print|dump|copy)\s+[^\n]{0,20}?(~/\.ssh|id_rsa|id_ed25519|\.aws/credentials|\.netrc|\.npmrc|\.pypirc)`),
+		"reads credential files",
+	},
+	{
+		InjectionExfiltration,
+		regexp.MustCompile(`(?i)\b(printenv|env)\s*\|\s*(curl|nc|wget)\b`),
+		"pipes environment variables to the network",
+	},
+}
+
+var (
+	tagChars      = regexp.MustCompile(`[\x{E0000}-\x{E007F}]+`)
+	zeroWidth     = regexp.MustCompile(`[\x{200B}-\x{200D}\x{2060}-\x{2064}\x{00AD}\x{FEFF}]+`)
+	bidiOverrides = regexp.MustCompile(`[\x{202A}-\x{202E}\x{2066}-\x{2069}]+`)
+)
+
+// ScanInjection finds prompt-injection style content in text, such as a
+// skill body that Claude reads verbatim on invocation. Spans are returned in
+// order of their start offset. A byte order mark at the very start of text is
+// not reported.
+func ScanInjection(text string) []Injection {
+	var found []Injection
+	for _, p := range injectionPatterns {
+		for _, loc := range p.pattern.FindAllStringIndex(text, -1) {
+			found = append(found, Injection{Kind: p.kind, Start: loc[0], End: loc[1], Message: p.message})
+		}
+	}
+
+	for _, loc := range tagChars.FindAllStringIndex(text, -1) {
+		found = append(found, Injection{
+			Kind:    InjectionInvisible,
+			Start:   loc[0],
+			End:     loc[1],
+			Message: fmt.Sprintf("invisible tag characters spell %q", decodeTags(text[loc[0]:loc[1]])),
+		})
+	}
+	for _, loc := range zeroWidth.FindAllStringIndex(text, -1) {
+		if loc[0] == 0 && strings.HasPrefix(text, "\uFEFF") {
+			if loc[1] == len("\uFEFF") {
+				continue
+			}
+			loc[0] = len("\uFEFF")
+		}
+		found = append(found, Injection{
+			Kind:    InjectionInvisible,
+			Start:   loc[0],
+			End:     loc[1],
+			Message: fmt.Sprintf("contains invisible character U+%04X", []rune(text[loc[0]:loc[1]])[0]),
+		})
+	}
+	for _, loc := range bidiOverrides.FindAllStringIndex(text, -1) {
+		found = append(found, Injection{
+			Kind:    InjectionBidi,
+			Start:   loc[0],
+			End:     loc[1],
+			Message: fmt.Sprintf("contains bidirectional override U+%04X", []rune(text[loc[0]:loc[1]])[0]),
+		})
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
+
+// decodeTags maps Unicode tag characters back to the ASCII they mirror.
+func decodeTags(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if c := r - 0xE0000; c >= 0x20 && c < 0x7F {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// IsInvisible reports whether r is one of the characters ScanInjection
+// reports as invisible or as a bidirectional override.
+func IsInvisible(r rune) bool {
+	s := string(r)
+	return tagChars.MatchString(s) || zeroWidth.MatchString(s) || bidiOverrides.MatchString(s)
+}
+
+// injectionFindings converts injection spans in a file into audit findings.
+func injectionFindings(path, text string) []Finding {
+	var findings []Finding
+	lastInvisibleLine := 0
+	for _, inj := range ScanInjection(text) {
+		line := lineAt(text, inj.Start)
+		f := Finding{Path: path, Line: line, Message: inj.Message}
+		switch inj.Kind {
+		case InjectionOverride:
+			f.Rule, f.Severity = RulePromptInjection, High
+			f.Excerpt = excerpt(text, inj.Start, inj.End)
+		case InjectionExfiltration:
+			f.Rule, f.Severity = RulePromptInjection, Critical
+			f.Excerpt = excerpt(text, inj.Start, inj.End)
+		default:
+			// Report invisible characters once per line to keep the output readable.
+			if line == lastInvisibleLine {
+				continue
+			}
+			lastInvisibleLine = line
+			f.Rule, f.Severity = RuleInvisibleChars, High
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
@@ -0,0 +1,194 @@
+// Package backend implements the mechanisms skillex uses to disable skills.
+//
+// The rename backend, the default, renames SKILL.md to SKILL.md.disabled
+// inside the skill's directory, which for plugin skills is the plugin's
+// installPath. A plugin update silently undoes that. The state backend
+// renames too, but also records disabled skill IDs in the skillex state file
+// so they can be detected and re-applied after an update. The settings
+// backend leaves skill files alone: it adds a Skill(...) rule to
+// permissions.deny in Claude Code's settings.json, which plugin updates
+// never touch.
+package backend
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/journal"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// Backend names, as stored in the state file.
+const (
+	NameRename   = "rename"
+	NameState    = "state"
+	NameSettings = "settings"
+)
+
+// Names lists the available backends, default first.
+var Names = []string{NameRename, NameState, NameSettings}
+
+// Backend disables and enables skills.
+type Backend interface {
+	// Name identifies the backend in the state file and the CLI.
+	Name() string
+	// Toggle flips the skill's enabled state, updating it in place, and
+	// returns the file operations it made, for the journal.
+	Toggle(skill *discovery.Skill) ([]journal.Action, error)
+	// Mark applies the backend's record to freshly discovered skills: it
+	// sets Reverted on skills recorded as disabled that are enabled on disk
+	// again, or clears Enabled on skills disabled outside their files.
+	Mark(skills []discovery.Skill)
+}
+
+// Load returns the backend selected in the state file at stateFile.
+// settingsFile is Claude Code's settings.json, used by the settings backend.
+func Load(stateFile, settingsFile string) (Backend, error) {
+	st, err := state.Load(stateFile)
+	if err != nil {
+		return nil, err
+	}
+	return For(st, stateFile, settingsFile), nil
+}
+
+// For returns the backend selected in st, which was read from stateFile.
+func For(st *state.State, stateFile, settingsFile string) Backend {
+	switch st.Backend {
+	case NameState:
+		return Recorded{StateFile: stateFile}
+	case NameSettings:
+		return Settings{SettingsFile: settingsFile, StateFile: stateFile}
+	}
+	return Rename{}
+}
+
+// Select switches st to the named backend. Switching to the state backend
+// records the skills currently disabled on disk, so they are kept across
+// plugin updates from then on. Skills disabled before switching to the
+// settings backend stay renamed until they are enabled.
+func Select(st *state.State, name string, skills []discovery.Skill) error {
+	switch name {
+	case NameRename:
+		st.Backend, st.Disabled = "", nil
+	case NameState:
+		st.Backend = NameState
+		disabled := make(map[string]bool)
+		for _, id := range st.Disabled {
+			disabled[id] = true
+		}
+		for _, s := range skills {
+			if !s.Enabled {
+				disabled[s.ID()] = true
+			}
+		}
+		st.Disabled = sortedKeys(disabled)
+	case NameSettings:
+		st.Backend, st.Disabled = NameSettings, nil
+	default:
+		return fmt.Errorf("unknown backend %q (want one of %v)", name, Names)
+	}
+	return nil
+}
+
+// Reapply disables every skill marked Reverted and returns the file
+// operations made. It stops at the first failure, returning what was done
+// so far.
+func Reapply(b Backend, skills []discovery.Skill) ([]journal.Action, error) {
+	var actions []journal.Action
+	for i := range skills {
+		if !skills[i].Reverted || !skills[i].Enabled {
+			continue
+		}
+		done, err := b.Toggle(&skills[i])
+		actions = append(actions, done...)
+		if err != nil {
+			return actions, fmt.Errorf("%s: %w", skills[i].ID(), err)
+		}
+		skills[i].Reverted = false
+	}
+	return actions, nil
+}
+
+// Rename is the default backend: the skill file's name is the only record.
+type Rename struct{}
+
+func (Rename) Name() string { return NameRename }
+
+func (Rename) Toggle(skill *discovery.Skill) ([]journal.Action, error) {
+	from := skill.FilePath
+	if err := discovery.ToggleSkill(skill); err != nil {
+		return nil, err
+	}
+	return []journal.Action{journal.Rename(from, skill.FilePath)}, nil
+}
+
+// Mark is a no-op: without a record there is nothing to compare against.
+func (Rename) Mark([]discovery.Skill) {}
+
+// Recorded is the state backend. It renames like Rename, so it still changes
+// files inside plugin install directories, and also keeps the IDs of disabled
+// skills in the state file.
+type Recorded struct {
+	StateFile string
+}
+
+func (Recorded) Name() string { return NameState }
+
+func (r Recorded) Toggle(skill *discovery.Skill) ([]journal.Action, error) {
+	from := skill.FilePath
+	toggled := false
+	before, after, err := state.Update(r.StateFile, func(st *state.State) error {
+		if err := discovery.ToggleSkill(skill); err != nil {
+			return err
+		}
+		toggled = true
+
+		disabled := make(map[string]bool, len(st.Disabled))
+		for _, id := range st.Disabled {
+			disabled[id] = true
+		}
+		disabled[skill.ID()] = !skill.Enabled
+		st.Disabled = sortedKeys(disabled)
+		return nil
+	})
+	if err != nil {
+		if toggled {
+			// Keep disk and record in step.
+			_ = discovery.ToggleSkill(skill)
+		}
+		return nil, err
+	}
+	skill.Reverted = false
+
+	return []journal.Action{
+		journal.Rename(from, skill.FilePath),
+		journal.Write(r.StateFile, before, after),
+	}, nil
+}
+
+func (r Recorded) Mark(skills []discovery.Skill) {
+	st, err := state.Load(r.StateFile)
+	if err != nil {
+		return
+	}
+	disabled := make(map[string]bool, len(st.Disabled))
+	for _, id := range st.Disabled {
+		disabled[id] = true
+	}
+	for i := range skills {
+		skills[i].Reverted = skills[i].Enabled && disabled[skills[i].ID()]
+	}
+}
+
+// sortedKeys returns the keys of m whose value is true, sorted.
+func sortedKeys(m map[string]bool) []string {
+	var keys []string
+	for k, ok := range m {
+		if ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
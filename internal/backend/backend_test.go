@@ -0,0 +1,113 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// discoverOne writes an enabled skill named name into a skills directory and
+// discovers it.
+func discoverOne(t *testing.T, dir, name string) []discovery.Skill {
+	t.Helper()
+	skillDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: "+name+"\n---\nBody.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return rediscover(t, dir)
+}
+
+func rediscover(t *testing.T, dir string) []discovery.Skill {
+	t.Helper()
+	pluginsFile := filepath.Join(t.TempDir(), "installed_plugins.json")
+	if err := os.WriteFile(pluginsFile, []byte(`{"version": 2, "plugins": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	skills, err := discovery.Discover(pluginsFile, []discovery.LocalSkillsDir{{Path: dir, Name: "p"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return skills
+}
+
+func TestRecordedSurvivesUpdate(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	skills := discoverOne(t, dir, "a")
+
+	b := Recorded{StateFile: stateFile}
+	actions, err := b.Toggle(&skills[0])
+	if err != nil {
+		t.Fatalf("Toggle() error: %v", err)
+	}
+	if skills[0].Enabled || len(actions) != 2 {
+		t.Fatalf("expected skill disabled with a rename and a state write, got %+v", actions)
+	}
+	st, _ := state.Load(stateFile)
+	if len(st.Disabled) != 1 || st.Disabled[0] != "p:a" {
+		t.Errorf("expected p:a recorded as disabled, got %v", st.Disabled)
+	}
+
+	// A plugin update restores SKILL.md.
+	if err := os.Remove(skills[0].FilePath); err != nil {
+		t.Fatal(err)
+	}
+	skills = discoverOne(t, dir, "a")
+	b.Mark(skills)
+	if !skills[0].Reverted {
+		t.Fatal("expected skill marked reverted")
+	}
+
+	if _, err := Reapply(b, skills); err != nil {
+		t.Fatalf("Reapply() error: %v", err)
+	}
+	if skills[0].Enabled || skills[0].Reverted {
+		t.Errorf("expected skill disabled again, got %+v", skills[0])
+	}
+
+	// Enabling removes the record.
+	if _, err := b.Toggle(&skills[0]); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := state.Load(stateFile); len(st.Disabled) != 0 {
+		t.Errorf("expected no recorded skills, got %v", st.Disabled)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	skills := []discovery.Skill{
+		{Name: "a", Plugin: "p", Enabled: false},
+		{Name: "b", Plugin: "p", Enabled: true},
+	}
+	st := &state.State{}
+	if err := Select(st, NameState, skills); err != nil {
+		t.Fatal(err)
+	}
+	if For(st, "", "").Name() != NameState || len(st.Disabled) != 1 || st.Disabled[0] != "p:a" {
+		t.Errorf("expected state backend seeded with p:a, got %+v", st)
+	}
+
+	if err := Select(st, NameRename, skills); err != nil {
+		t.Fatal(err)
+	}
+	if For(st, "", "").Name() != NameRename || st.Disabled != nil {
+		t.Errorf("expected rename backend without records, got %+v", st)
+	}
+
+	if err := Select(st, NameSettings, skills); err != nil {
+		t.Fatal(err)
+	}
+	if For(st, "", "").Name() != NameSettings || st.Disabled != nil {
+		t.Errorf("expected settings backend without records, got %+v", st)
+	}
+
+	if err := Select(st, "nope", skills); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
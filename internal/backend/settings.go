@@ -0,0 +1,260 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/journal"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// Settings is the settings backend. It disables a skill with a
+// permissions.deny rule in Claude Code's user settings, so skill files,
+// including those inside plugin install directories, are never renamed.
+// Skills still renamed by another backend are enabled by renaming them back.
+type Settings struct {
+	// SettingsFile is Claude Code's settings.json.
+	SettingsFile string
+	// StateFile is only locked, never written: taking the lock next to
+	// skillex's own files keeps lock files out of Claude Code's directory.
+	StateFile string
+}
+
+func (Settings) Name() string { return NameSettings }
+
+// Rule returns the permission rule that denies skill. Claude Code names
+// plugin skills "plugin:skill" and all other skills by name alone.
+func Rule(skill discovery.Skill) string {
+	if skill.PluginDir != "" {
+		return "Skill(" + skill.ID() + ")"
+	}
+	return "Skill(" + skill.Name + ")"
+}
+
+func (s Settings) Toggle(skill *discovery.Skill) ([]journal.Action, error) {
+	enable := !skill.Enabled
+	renamed := strings.HasSuffix(skill.FilePath, ".disabled")
+	if err := discovery.Verify(skill); err != nil {
+		return nil, err
+	}
+
+	unlock, err := state.Lock(s.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	before, settings, err := readSettings(s.SettingsFile)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := settings.deny()
+	if err != nil {
+		return nil, err
+	}
+	rule := Rule(*skill)
+	denied := slices.Contains(deny, rule)
+
+	var actions []journal.Action
+	if enable && renamed {
+		from := skill.FilePath
+		if err := discovery.ToggleSkill(skill); err != nil {
+			return nil, err
+		}
+		actions = append(actions, journal.Rename(from, skill.FilePath))
+	}
+	if denied != enable {
+		// Nothing to write: the rule is already as wanted.
+		skill.Enabled = enable
+		return actions, nil
+	}
+
+	if enable {
+		deny = slices.DeleteFunc(deny, func(r string) bool { return r == rule })
+	} else {
+		deny = append(deny, rule)
+	}
+	after, err := settings.setDeny(deny)
+	if err == nil {
+		err = state.WriteFileAtomic(s.SettingsFile, after)
+	}
+	if err != nil {
+		if len(actions) > 0 {
+			// Keep the rename and the rule in step.
+			_ = discovery.ToggleSkill(skill)
+		}
+		return nil, fmt.Errorf("writing %s: %w", s.SettingsFile, err)
+	}
+	skill.Enabled = enable
+	return append(actions, journal.Write(s.SettingsFile, before, after)), nil
+}
+
+// Mark disables the skills that a deny rule keeps Claude Code from using.
+func (s Settings) Mark(skills []discovery.Skill) {
+	denied, err := s.Denied()
+	if err != nil {
+		return
+	}
+	for i := range skills {
+		if denied[Rule(skills[i])] {
+			skills[i].Enabled = false
+		}
+	}
+}
+
+// Denied returns the skill rules in the settings file's permissions.deny.
+func (s Settings) Denied() (map[string]bool, error) {
+	_, settings, err := readSettings(s.SettingsFile)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := settings.deny()
+	if err != nil {
+		return nil, err
+	}
+	denied := make(map[string]bool)
+	for _, r := range deny {
+		if strings.HasPrefix(r, "Skill(") {
+			denied[r] = true
+		}
+	}
+	return denied, nil
+}
+
+// readSettings reads the settings file at path. A missing file yields nil
+// data and an empty object.
+func readSettings(path string) ([]byte, *object, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, &object{}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading settings: %w", err)
+	}
+	var o object
+	if err := o.UnmarshalJSON(data); err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return data, &o, nil
+}
+
+// deny returns the rules in permissions.deny.
+func (o *object) deny() ([]string, error) {
+	var perms object
+	if raw, ok := o.get("permissions"); ok {
+		if err := perms.UnmarshalJSON(raw); err != nil {
+			return nil, fmt.Errorf("parsing permissions: %w", err)
+		}
+	}
+	var deny []string
+	if raw, ok := perms.get("deny"); ok {
+		if err := json.Unmarshal(raw, &deny); err != nil {
+			return nil, fmt.Errorf("parsing permissions.deny: %w", err)
+		}
+	}
+	return deny, nil
+}
+
+// setDeny replaces permissions.deny with rules and returns the encoded
+// settings. Every other key is kept as it was, in its place.
+func (o *object) setDeny(rules []string) ([]byte, error) {
+	var perms object
+	if raw, ok := o.get("permissions"); ok {
+		if err := perms.UnmarshalJSON(raw); err != nil {
+			return nil, fmt.Errorf("parsing permissions: %w", err)
+		}
+	}
+	if rules == nil {
+		rules = []string{}
+	}
+	deny, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+	perms.set("deny", deny)
+	raw, err := perms.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	o.set("permissions", raw)
+
+	data, err := o.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// object is a JSON object that keeps its keys in order, so rewriting the
+// settings file only changes what skillex edits.
+type object struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+func (o *object) get(key string) (json.RawMessage, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+func (o *object) set(key string, value json.RawMessage) {
+	if o.values == nil {
+		o.values = make(map[string]json.RawMessage)
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *object) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("expected an object")
+	}
+	*o = object{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		o.set(tok.(string), value)
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(o.values[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
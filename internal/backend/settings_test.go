@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+func TestSettingsToggle(t *testing.T) {
+	dir := t.TempDir()
+	settingsFile := filepath.Join(t.TempDir(), "settings.json")
+	orig := `{
+  "model": "opus",
+  "permissions": {
+    "allow": ["Bash(ls)"],
+    "deny": ["Read(.env)"]
+  },
+  "env": {}
+}
+`
+	if err := os.WriteFile(settingsFile, []byte(orig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	skills := discoverOne(t, dir, "a")
+	b := Settings{SettingsFile: settingsFile, StateFile: filepath.Join(t.TempDir(), "state.json")}
+
+	actions, err := b.Toggle(&skills[0])
+	if err != nil {
+		t.Fatalf("Toggle() error: %v", err)
+	}
+	if skills[0].Enabled || len(actions) != 1 {
+		t.Fatalf("expected skill disabled with one settings write, got %+v", actions)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a", "SKILL.md")); err != nil {
+		t.Errorf("expected SKILL.md left in place: %v", err)
+	}
+	data, _ := os.ReadFile(settingsFile)
+	want := `{
+  "model": "opus",
+  "permissions": {
+    "allow": [
+      "Bash(ls)"
+    ],
+    "deny": [
+      "Read(.env)",
+      "Skill(a)"
+    ]
+  },
+  "env": {}
+}
+`
+	if string(data) != want {
+		t.Errorf("settings =\n%s\nwant\n%s", data, want)
+	}
+
+	// Rediscovery finds SKILL.md; Mark applies the rule.
+	skills = rediscover(t, dir)
+	b.Mark(skills)
+	if skills[0].Enabled {
+		t.Fatal("expected the denied skill marked disabled")
+	}
+
+	if _, err := b.Toggle(&skills[0]); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(settingsFile)
+	if !skills[0].Enabled || strings.Contains(string(data), "Skill(a)") {
+		t.Errorf("expected the rule removed, got\n%s", data)
+	}
+}
+
+func TestSettingsEnablesRenamedSkill(t *testing.T) {
+	dir := t.TempDir()
+	settingsFile := filepath.Join(t.TempDir(), "settings.json")
+	skills := discoverOne(t, dir, "a")
+	if err := discovery.ToggleSkill(&skills[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	b := Settings{SettingsFile: settingsFile, StateFile: filepath.Join(t.TempDir(), "state.json")}
+	actions, err := b.Toggle(&skills[0])
+	if err != nil {
+		t.Fatalf("Toggle() error: %v", err)
+	}
+	if !skills[0].Enabled || len(actions) != 1 || filepath.Base(skills[0].FilePath) != "SKILL.md" {
+		t.Errorf("expected the skill renamed back and nothing else, got %+v", actions)
+	}
+	if _, err := os.Stat(settingsFile); !os.IsNotExist(err) {
+		t.Errorf("expected no settings file written, got %v", err)
+	}
+}
+
+func TestRule(t *testing.T) {
+	local := discovery.Skill{Name: "a", Plugin: "local"}
+	plugin := discovery.Skill{Name: "a", Plugin: "p", PluginDir: "/plugins/p"}
+	if got := Rule(local); got != "Skill(a)" {
+		t.Errorf("Rule(local) = %q", got)
+	}
+	if got := Rule(plugin); got != "Skill(p:a)" {
+		t.Errorf("Rule(plugin) = %q", got)
+	}
+}
@@ -0,0 +1,162 @@
+// Package budget measures skill descriptions against the character budget
+// Claude Code sets aside for them, shared by the analytics panel and the
+// reports.
+package budget
+
+import (
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// Limit is the fallback character budget for all skill descriptions
+// combined in Claude Code's available_skills system prompt section.
+// The actual limit scales dynamically at 2% of the model's context window,
+// with 16,000 chars as the documented fallback. Skills that don't fit are
+// silently excluded with no warning.
+// Source: https://github.com/anthropics/claude-code/issues/13099
+const Limit = 16_000
+
+// EnvVar is the environment variable Claude Code reads to raise the budget.
+const EnvVar = "SLASH_COMMAND_TOOL_CHAR_BUDGET"
+
+// ResolveLimit returns the budget in effect: EnvVar when it holds a positive
+// number, Limit otherwise.
+func ResolveLimit() int {
+	if n, err := strconv.Atoi(os.Getenv(EnvVar)); err == nil && n > 0 {
+		return n
+	}
+	return Limit
+}
+
+// ContentWordLimit is the SKILL.md length, in words, above which a skill is
+// considered verbose: its whole body is loaded into context on every use.
+const ContentWordLimit = 500
+
+// Level is how full the budget is.
+type Level int
+
+const (
+	// Healthy means every description fits with room to spare.
+	Healthy Level = iota
+	// Tight means more of the budget is used than the tight threshold, 80%
+	// by default.
+	Tight
+	// Exceeded means some skills no longer fit and are dropped.
+	Exceeded
+)
+
+// Thresholds are the budget percentages at which the levels start.
+type Thresholds struct {
+	// Tight is exceeded, not reached: at exactly Tight percent the budget
+	// is still healthy.
+	Tight float64
+	// Exceeded is reached at exactly Exceeded percent.
+	Exceeded float64
+}
+
+// DefaultThresholds are the levels shown by the analytics panel.
+var DefaultThresholds = Thresholds{Tight: 80, Exceeded: 100}
+
+// Assess returns the level for total description chars against limit with
+// the default thresholds.
+func Assess(total, limit int) Level {
+	return DefaultThresholds.Assess(total, limit)
+}
+
+// Assess returns the level for total description chars against limit.
+func (t Thresholds) Assess(total, limit int) Level {
+	pct := float64(total) * 100 / float64(limit)
+	switch {
+	case pct >= t.Exceeded:
+		return Exceeded
+	case pct > t.Tight:
+		return Tight
+	}
+	return Healthy
+}
+
+// String returns the level's name: healthy, tight or exceeded.
+func (l Level) String() string {
+	switch l {
+	case Tight:
+		return "tight"
+	case Exceeded:
+		return "exceeded"
+	}
+	return "healthy"
+}
+
+// Advice explains the level in one line.
+func (l Level) Advice() string {
+	switch l {
+	case Tight:
+		return "Tight: adding more skills risks silent exclusion"
+	case Exceeded:
+		return "Exceeded: some skills won't be visible to Claude"
+	}
+	return "Healthy: all descriptions fit into Claude's context"
+}
+
+// Total returns the sum of description lengths across loaded skills.
+// Claude Code silently stops loading skills when this total exceeds the
+// budget. Disabled and nested skills are excluded because Claude never sees
+// them.
+func Total(skills []discovery.Skill) int {
+	total := 0
+	for _, s := range skills {
+		if s.Loaded() {
+			total += len(s.Description)
+		}
+	}
+	return total
+}
+
+// Contributor is one skill's share of the budget.
+type Contributor struct {
+	ID    string
+	Chars int
+}
+
+// Largest returns the loaded skills with the longest descriptions, longest
+// first, at most n of them; n <= 0 returns all.
+func Largest(skills []discovery.Skill, n int) []Contributor {
+	var out []Contributor
+	for _, s := range skills {
+		if s.Loaded() {
+			out = append(out, Contributor{ID: s.ID(), Chars: len(s.Description)})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Chars > out[j].Chars })
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// Disabled returns the count and total description chars of disabled
+// skills, the budget they would take if enabled. Nested skills are left
+// out: enabling them would not load them either.
+func Disabled(skills []discovery.Skill) (count int, chars int) {
+	for _, s := range skills {
+		if !s.Enabled && s.Category == "" {
+			count++
+			chars += len(s.Description)
+		}
+	}
+	return
+}
+
+// NotLoaded returns the number of skills nested in category folders, which
+// Claude Code does not load whether enabled or not.
+func NotLoaded(skills []discovery.Skill) int {
+	count := 0
+	for _, s := range skills {
+		if s.Category != "" {
+			count++
+		}
+	}
+	return count
+}
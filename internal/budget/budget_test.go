@@ -0,0 +1,94 @@
+package budget
+
+import (
+	"testing"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+func TestTotalExcludesDisabledSkills(t *testing.T) {
+	skills := []discovery.Skill{
+		{Name: "a", Description: "AAAAAAAAAA", Enabled: true},  // 10 chars
+		{Name: "b", Description: "BBBBBBBBBB", Enabled: false}, // 10 chars, disabled
+	}
+
+	total := Total(skills)
+	if total != 10 {
+		t.Errorf("expected Total=10 (excluding disabled), got %d", total)
+	}
+}
+
+func TestNestedSkillsAreNotCounted(t *testing.T) {
+	skills := []discovery.Skill{
+		{Name: "a", Plugin: "p", Description: "12345", Enabled: true},
+		{Name: "b", Plugin: "p", Description: "1234567890", Enabled: true, Category: "ops"},
+		{Name: "c", Plugin: "p", Description: "123", Category: "ops/old"},
+	}
+	if total := Total(skills); total != 5 {
+		t.Errorf("expected Total=5 (excluding nested), got %d", total)
+	}
+	if got := Largest(skills, 0); len(got) != 1 || got[0].ID != "p:a" {
+		t.Errorf("expected only the loaded skill among the largest, got %+v", got)
+	}
+	if count, _ := Disabled(skills); count != 0 {
+		t.Errorf("expected a nested disabled skill not to count as savings, got %d", count)
+	}
+	if n := NotLoaded(skills); n != 2 {
+		t.Errorf("expected 2 skills not loaded, got %d", n)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	skills := []discovery.Skill{
+		{Name: "a", Description: "12345", Enabled: true},
+		{Name: "b", Description: "1234567890", Enabled: false},
+		{Name: "c", Description: "123", Enabled: false},
+	}
+
+	count, chars := Disabled(skills)
+	if count != 2 {
+		t.Errorf("expected 2 disabled skills, got %d", count)
+	}
+	if chars != 13 {
+		t.Errorf("expected 13 disabled chars, got %d", chars)
+	}
+}
+
+func TestAssess(t *testing.T) {
+	for total, want := range map[int]Level{0: Healthy, 12_800: Healthy, 12_801: Tight, 16_000: Exceeded} {
+		if got := Assess(total, Limit); got != want {
+			t.Errorf("Assess(%d) = %v, want %v", total, got, want)
+		}
+	}
+}
+
+func TestThresholdsAndLimit(t *testing.T) {
+	strict := Thresholds{Tight: 50, Exceeded: 90}
+	for total, want := range map[int]Level{50: Healthy, 51: Tight, 89: Tight, 90: Exceeded} {
+		if got := strict.Assess(total, 100); got != want {
+			t.Errorf("Assess(%d) = %v, want %v", total, got, want)
+		}
+	}
+
+	t.Setenv(EnvVar, "30000")
+	if got := ResolveLimit(); got != 30000 {
+		t.Errorf("expected the environment to raise the limit, got %d", got)
+	}
+	t.Setenv(EnvVar, "lots")
+	if got := ResolveLimit(); got != Limit {
+		t.Errorf("expected the default limit for an invalid value, got %d", got)
+	}
+}
+
+func TestLargest(t *testing.T) {
+	skills := []discovery.Skill{
+		{Name: "a", Plugin: "p", Description: "12", Enabled: true},
+		{Name: "b", Plugin: "p", Description: "12345", Enabled: true},
+		{Name: "c", Plugin: "p", Description: "1234567890"},
+		{Name: "d", Plugin: "p", Description: "123", Enabled: true},
+	}
+	got := Largest(skills, 2)
+	if len(got) != 2 || got[0] != (Contributor{"p:b", 5}) || got[1] != (Contributor{"p:d", 3}) {
+		t.Errorf("expected b then d, got %+v", got)
+	}
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/audit"
+)
+
+type auditReport struct {
+	Skill    string         `json:"skill"`
+	Plugin   string         `json:"plugin"`
+	Path     string         `json:"path"`
+	Enabled  bool           `json:"enabled"`
+	Findings []auditFinding `json:"findings"`
+}
+
+type auditFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+	Excerpt  string `json:"excerpt,omitempty"`
+}
+
+func runAudit(env Env, args []string) int {
+	fs := newFlagSet(env, "audit", "[flags] [skill...]")
+	failOn := fs.String("fail-on", "high", "exit with code 2 if any finding is at or above this severity (low, medium, high, critical, or none)")
+	minSeverity := fs.String("min-severity", "low", "hide findings below this severity")
+	format := fs.String("format", "text", "output format: text or json")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	min, err := audit.ParseSeverity(*minSeverity)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex audit: %v\n", err)
+		return ExitError
+	}
+	gate := *failOn != "none"
+	var threshold audit.Severity
+	if gate {
+		if threshold, err = audit.ParseSeverity(*failOn); err != nil {
+			fmt.Fprintf(env.Stderr, "skillex audit: %v\n", err)
+			return ExitError
+		}
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(env.Stderr, "skillex audit: unknown format %q\n", *format)
+		return ExitError
+	}
+
+	skills, err := loadSkills(env, fs.Args())
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex audit: %v\n", err)
+		return ExitError
+	}
+
+	var reports []auditReport
+	counts := make(map[audit.Severity]int)
+	failed := false
+	for _, s := range skills {
+		report := auditReport{Skill: s.Name, Plugin: s.Plugin, Path: s.FilePath, Enabled: s.Enabled, Findings: []auditFinding{}}
+		for _, f := range audit.Scan(s) {
+			if f.Severity < min {
+				continue
+			}
+			counts[f.Severity]++
+			if gate && f.Severity >= threshold {
+				failed = true
+			}
+			report.Findings = append(report.Findings, auditFinding{
+				Rule:     f.Rule,
+				Severity: f.Severity.String(),
+				Path:     f.Path,
+				Line:     f.Line,
+				Message:  f.Message,
+				Excerpt:  f.Excerpt,
+			})
+		}
+		reports = append(reports, report)
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(env.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			fmt.Fprintf(env.Stderr, "skillex audit: %v\n", err)
+			return ExitError
+		}
+	} else {
+		printAuditText(env, reports, counts)
+	}
+
+	if failed {
+		return ExitFailed
+	}
+	return ExitOK
+}
+
+func printAuditText(env Env, reports []auditReport, counts map[audit.Severity]int) {
+	for _, r := range reports {
+		if len(r.Findings) == 0 {
+			continue
+		}
+		status := ""
+		if !r.Enabled {
+			status = " (disabled)"
+		}
+		fmt.Fprintf(env.Stdout, "%s:%s%s\n", r.Plugin, r.Skill, status)
+		for _, f := range r.Findings {
+			loc := f.Path
+			if f.Line > 0 {
+				loc = fmt.Sprintf("%s:%d", f.Path, f.Line)
+			}
+			fmt.Fprintf(env.Stdout, "  %-8s  %-22s %s: %s\n", strings.ToUpper(f.Severity), f.Rule, loc, f.Message)
+			if f.Excerpt != "" {
+				fmt.Fprintf(env.Stdout, "  %-8s  %-22s %s\n", "", "", f.Excerpt)
+			}
+		}
+		fmt.Fprintln(env.Stdout)
+	}
+
+	var parts []string
+	for sev := audit.Critical; sev >= audit.Low; sev-- {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[sev], sev))
+	}
+	fmt.Fprintf(env.Stdout, "Audited %d skill(s): %s\n", len(reports), strings.Join(parts, ", "))
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/backend"
+	"github.com/smauermann/skillex/internal/config"
+	"github.com/smauermann/skillex/internal/profile"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+func runBackend(env Env, args []string) int {
+	fs := newFlagSet(env, "backend", "["+strings.Join(backend.Names, "|")+"]")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return ExitError
+	}
+
+	st, err := state.Load(env.StateFile)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex backend: %v\n", err)
+		return ExitError
+	}
+	settingsFile := config.FilesOf(env.StateFile).ClaudeSettings()
+	prev := backend.For(st, env.StateFile, settingsFile)
+	if fs.NArg() == 0 {
+		fmt.Fprintf(env.Stdout, "%s\n", prev.Name())
+		switch b := prev.(type) {
+		case backend.Recorded:
+			fmt.Fprintf(env.Stdout, "%d skill(s) recorded as disabled\n", len(st.Disabled))
+		case backend.Settings:
+			denied, err := b.Denied()
+			if err != nil {
+				fmt.Fprintf(env.Stderr, "skillex backend: %v\n", err)
+				return ExitError
+			}
+			fmt.Fprintf(env.Stdout, "%d skill(s) denied in %s\n", len(denied), settingsFile)
+		}
+		return ExitOK
+	}
+
+	name := fs.Arg(0)
+	skills, err := loadSkills(env, nil)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex backend: %v\n", err)
+		return ExitError
+	}
+	err = updateState(env, "use the "+name+" backend", func(cur *state.State) error {
+		if err := backend.Select(cur, name, skills); err != nil {
+			return err
+		}
+		st = cur
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex backend: %v\n", err)
+		return ExitError
+	}
+	fmt.Fprintf(env.Stdout, "Using the %s backend.\n", name)
+	switch name {
+	case backend.NameState:
+		fmt.Fprintf(env.Stdout, "Recorded %d disabled skill(s); run 'skillex reapply' after plugin updates.\n", len(st.Disabled))
+		fmt.Fprintln(env.Stdout, "Skills are still disabled by renaming SKILL.md inside each plugin's install directory.")
+	case backend.NameSettings:
+		fmt.Fprintf(env.Stdout, "Skills are now disabled with Skill(...) rules in the permissions.deny list of %s; skill files stay untouched.\n", settingsFile)
+		renamed := 0
+		for _, s := range skills {
+			if !s.Enabled && strings.HasSuffix(s.FilePath, ".disabled") {
+				renamed++
+			}
+		}
+		if renamed > 0 {
+			fmt.Fprintf(env.Stdout, "%d skill(s) disabled by renaming stay renamed until you enable them.\n", renamed)
+		}
+	}
+	if b, ok := prev.(backend.Settings); ok && name != backend.NameSettings {
+		if denied, err := b.Denied(); err == nil && len(denied) > 0 {
+			fmt.Fprintf(env.Stderr, "skillex backend: %d skill(s) stay denied in %s; enable them before switching, or remove their Skill(...) rules\n", len(denied), settingsFile)
+		}
+	}
+	return ExitOK
+}
+
+func runReapply(env Env, args []string) int {
+	fs := newFlagSet(env, "reapply", "[flags]")
+	check := fs.Bool("check", false, "only list reverted skills; exit with code 2 if there are any")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	b, err := backend.Load(env.StateFile, config.FilesOf(env.StateFile).ClaudeSettings())
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex reapply: %v\n", err)
+		return ExitError
+	}
+	if b.Name() == backend.NameRename {
+		fmt.Fprintln(env.Stderr, "skillex reapply: the rename backend keeps no record to reapply; switch with 'skillex backend state'")
+		return ExitError
+	}
+	skills, err := loadSkills(env, nil)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex reapply: %v\n", err)
+		return ExitError
+	}
+
+	var reverted []string
+	for _, s := range skills {
+		if s.Reverted {
+			reverted = append(reverted, s.ID())
+		}
+	}
+	if len(reverted) == 0 {
+		fmt.Fprintln(env.Stdout, "No reverted skills.")
+		return ExitOK
+	}
+	if *check {
+		for _, id := range reverted {
+			fmt.Fprintf(env.Stdout, "reverted %s\n", id)
+		}
+		return ExitFailed
+	}
+
+	// Reapply stops at the first failure; what it did so far is journaled
+	// either way.
+	actions, err := backend.Reapply(b, skills)
+	rerr := profile.Record(config.FilesOf(env.StateFile).Journal(), "reapply disabled skills", actions)
+	switch {
+	case err != nil:
+		if rerr != nil {
+			err = fmt.Errorf("%w; %w", err, rerr)
+		}
+		fmt.Fprintf(env.Stderr, "skillex reapply: %v\n", err)
+		return ExitError
+	case errors.Is(rerr, profile.ErrNotJournaled):
+		for _, id := range reverted {
+			fmt.Fprintf(env.Stdout, "disable %s\n", id)
+		}
+		fmt.Fprintf(env.Stderr, "skillex reapply: disabled %d skill(s) again: %v\n", len(reverted), rerr)
+		return ExitError
+	}
+	for _, id := range reverted {
+		fmt.Fprintf(env.Stdout, "disable %s\n", id)
+	}
+	fmt.Fprintf(env.Stdout, "Disabled %d skill(s) again. Start a new Claude session to apply.\n", len(reverted))
+	return ExitOK
+}
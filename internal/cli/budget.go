@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/smauermann/skillex/internal/budget"
+)
+
+type budgetReport struct {
+	Total   int     `json:"total"`
+	Limit   int     `json:"limit"`
+	Percent float64 `json:"percent"`
+	Level   string  `json:"level"`
+	Skills  int     `json:"enabledSkills"`
+	// NotLoaded counts skills nested in category folders, which Claude
+	// Code does not load and the total leaves out.
+	NotLoaded int                 `json:"notLoaded,omitempty"`
+	Largest   []budgetContributor `json:"largest"`
+}
+
+type budgetContributor struct {
+	Skill string `json:"skill"`
+	Chars int    `json:"chars"`
+}
+
+func runBudget(env Env, args []string) int {
+	fs := newFlagSet(env, "budget", "[flags]")
+	check := fs.Bool("check", false, "exit with code 3 if the budget is tight and 2 if it is exceeded")
+	limit := fs.Int("limit", budget.ResolveLimit(), "description budget in chars (default from $"+budget.EnvVar+" or 16000)")
+	tight := fs.Float64("tight", budget.DefaultThresholds.Tight, "percentage of the limit above which the budget is tight")
+	exceeded := fs.Float64("exceeded", budget.DefaultThresholds.Exceeded, "percentage of the limit at which the budget is exceeded")
+	top := fs.Int("top", 10, "number of largest descriptions to list (0 for all)")
+	format := fs.String("format", "text", "output format: text or json")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if *limit <= 0 {
+		fmt.Fprintf(env.Stderr, "skillex budget: --limit must be positive\n")
+		return ExitError
+	}
+	if *tight <= 0 || *exceeded <= *tight {
+		fmt.Fprintf(env.Stderr, "skillex budget: need 0 < --tight < --exceeded\n")
+		return ExitError
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(env.Stderr, "skillex budget: unknown format %q\n", *format)
+		return ExitError
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(env.Stderr, "skillex budget: unexpected arguments\n")
+		return ExitError
+	}
+
+	skills, err := loadSkills(env, nil)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex budget: %v\n", err)
+		return ExitError
+	}
+
+	total := budget.Total(skills)
+	level := budget.Thresholds{Tight: *tight, Exceeded: *exceeded}.Assess(total, *limit)
+	report := budgetReport{
+		Total:     total,
+		Limit:     *limit,
+		Percent:   float64(total) * 100 / float64(*limit),
+		Level:     level.String(),
+		NotLoaded: budget.NotLoaded(skills),
+		Largest:   []budgetContributor{},
+	}
+	for _, s := range skills {
+		if s.Loaded() {
+			report.Skills++
+		}
+	}
+	for _, c := range budget.Largest(skills, *top) {
+		report.Largest = append(report.Largest, budgetContributor{Skill: c.ID, Chars: c.Chars})
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(env.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(env.Stderr, "skillex budget: %v\n", err)
+			return ExitError
+		}
+	} else {
+		fmt.Fprintf(env.Stdout, "Description budget: %d / %d chars (%.1f%%) across %d enabled skill(s)\n",
+			report.Total, report.Limit, report.Percent, report.Skills)
+		fmt.Fprintf(env.Stdout, "%s: %s\n", level, level.Advice())
+		if report.NotLoaded > 0 {
+			fmt.Fprintf(env.Stdout, "%d nested skill(s) not loaded by Claude Code and not counted\n", report.NotLoaded)
+		}
+		if len(report.Largest) > 0 {
+			fmt.Fprintf(env.Stdout, "\nLargest descriptions:\n")
+			for _, c := range report.Largest {
+				fmt.Fprintf(env.Stdout, "  %6d  %4.1f%%  %s\n", c.Chars, float64(c.Chars)*100/float64(*limit), c.Skill)
+			}
+		}
+	}
+
+	if !*check {
+		return ExitOK
+	}
+	switch level {
+	case budget.Exceeded:
+		return ExitFailed
+	case budget.Tight:
+		return ExitWarning
+	}
+	return ExitOK
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/smauermann/skillex/internal/budget"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/lint"
+)
+
+type checkReport struct {
+	Plugin   string         `json:"plugin"`
+	Version  string         `json:"version,omitempty"`
+	Path     string         `json:"path"`
+	Skills   []checkSkill   `json:"skills"`
+	Failures []checkFailure `json:"failures"`
+	Budget   checkBudget    `json:"budget"`
+}
+
+type checkSkill struct {
+	ID         string        `json:"id"`
+	Path       string        `json:"path"`
+	Activation string        `json:"activation"`
+	DescChars  int           `json:"descChars"`
+	Findings   []lintFinding `json:"findings"`
+}
+
+type lintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+}
+
+type checkFailure struct {
+	Dir   string `json:"dir"`
+	Error string `json:"error"`
+}
+
+type checkBudget struct {
+	// Plugin is the description chars the source adds on its own.
+	Plugin int `json:"plugin"`
+	// Total is Plugin plus the enabled installed skills with --with-installed.
+	Total   int     `json:"total"`
+	Limit   int     `json:"limit"`
+	Percent float64 `json:"percent"`
+	Level   string  `json:"level"`
+	// Replaced is set when an installed copy of the plugin was left out of
+	// Total in favour of the source.
+	Replaced bool `json:"replaced,omitempty"`
+}
+
+func runCheck(env Env, args []string) int {
+	fs := newFlagSet(env, "check", "--path dir [flags]")
+	path := fs.String("path", "", "plugin source tree to check: a directory with skills/ and optionally .claude-plugin/plugin.json")
+	withInstalled := fs.Bool("with-installed", false, "measure the budget together with the installed, enabled skills")
+	limit := fs.Int("limit", budget.ResolveLimit(), "description budget in chars (default from $"+budget.EnvVar+" or 16000)")
+	format := fs.String("format", "text", "output format: text or json")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if *path == "" {
+		fmt.Fprintf(env.Stderr, "skillex check: --path is required\n")
+		return ExitError
+	}
+	if *limit <= 0 {
+		fmt.Fprintf(env.Stderr, "skillex check: --limit must be positive\n")
+		return ExitError
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(env.Stderr, "skillex check: unknown format %q\n", *format)
+		return ExitError
+	}
+
+	skills, failures, err := discovery.DiscoverPlugin(*path)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex check: %v\n", err)
+		return ExitError
+	}
+	if len(skills) == 0 && len(failures) == 0 {
+		fmt.Fprintf(env.Stderr, "skillex check: no skills found in %s\n", *path)
+		return ExitError
+	}
+
+	report := checkReport{Path: *path, Skills: []checkSkill{}, Failures: []checkFailure{}}
+	if len(skills) > 0 {
+		report.Plugin, report.Version = skills[0].Plugin, skills[0].PluginVersion
+	} else {
+		report.Plugin = failures[0].Plugin
+	}
+
+	failed := len(failures) > 0
+	for _, s := range skills {
+		cs := checkSkill{
+			ID:         s.ID(),
+			Path:       s.FilePath,
+			Activation: s.ActivationStyle.String(),
+			DescChars:  len(s.Description),
+			Findings:   []lintFinding{},
+		}
+		for _, f := range lint.Check(s) {
+			if f.Severity == lint.Error {
+				failed = true
+			}
+			cs.Findings = append(cs.Findings, lintFinding{Rule: f.Rule, Severity: f.Severity.String(), Path: f.Path, Message: f.Message})
+		}
+		report.Skills = append(report.Skills, cs)
+	}
+	for _, f := range failures {
+		report.Failures = append(report.Failures, checkFailure{Dir: f.Dir, Error: f.Err.Error()})
+	}
+
+	measured := skills
+	if *withInstalled {
+		installed, _, err := discoverSkills(env)
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "skillex check: %v\n", err)
+			return ExitError
+		}
+		// An installed copy of the plugin is what the source would replace.
+		measured = append([]discovery.Skill(nil), skills...)
+		for _, s := range installed {
+			if s.Plugin == report.Plugin {
+				report.Budget.Replaced = true
+				continue
+			}
+			measured = append(measured, s)
+		}
+	}
+	report.Budget.Plugin = budget.Total(skills)
+	report.Budget.Total = budget.Total(measured)
+	report.Budget.Limit = *limit
+	report.Budget.Percent = float64(report.Budget.Total) * 100 / float64(*limit)
+	level := budget.Assess(report.Budget.Total, *limit)
+	report.Budget.Level = level.String()
+
+	if *format == "json" {
+		enc := json.NewEncoder(env.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(env.Stderr, "skillex check: %v\n", err)
+			return ExitError
+		}
+	} else {
+		printCheckText(env, report, *withInstalled, level)
+	}
+
+	switch {
+	case failed || level == budget.Exceeded:
+		return ExitFailed
+	case level == budget.Tight:
+		return ExitWarning
+	}
+	return ExitOK
+}
+
+func printCheckText(env Env, r checkReport, withInstalled bool, level budget.Level) {
+	name := r.Plugin
+	if r.Version != "" {
+		name += " " + r.Version
+	}
+	fmt.Fprintf(env.Stdout, "%s: %d skill(s) in %s\n\n", name, len(r.Skills), r.Path)
+
+	for _, s := range r.Skills {
+		fmt.Fprintf(env.Stdout, "  %-40s %5d chars  %s\n", s.ID, s.DescChars, s.Activation)
+		for _, f := range s.Findings {
+			loc := ""
+			if f.Path != "" {
+				loc = f.Path + ": "
+			}
+			fmt.Fprintf(env.Stdout, "    %-8s %-18s %s%s\n", f.Severity, f.Rule, loc, f.Message)
+		}
+	}
+	for _, f := range r.Failures {
+		fmt.Fprintf(env.Stdout, "  %-8s %s: %s\n", "error", f.Dir, f.Error)
+	}
+
+	b := r.Budget
+	fmt.Fprintln(env.Stdout)
+	if withInstalled {
+		fmt.Fprintf(env.Stdout, "Description budget with installed skills: %d / %d chars (%.1f%%), %d from %s\n",
+			b.Total, b.Limit, b.Percent, b.Plugin, r.Plugin)
+		if b.Replaced {
+			fmt.Fprintf(env.Stdout, "The installed %s is left out: the source replaces it.\n", r.Plugin)
+		}
+	} else {
+		fmt.Fprintf(env.Stdout, "Description budget: %d / %d chars (%.1f%%)\n", b.Total, b.Limit, b.Percent)
+	}
+	fmt.Fprintf(env.Stdout, "%s: %s\n", level, level.Advice())
+}
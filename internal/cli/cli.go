@@ -0,0 +1,195 @@
+// Package cli implements skillex's non-interactive subcommands. Running
+// skillex without arguments starts the TUI; any argument selects a command.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/backend"
+	"github.com/smauermann/skillex/internal/config"
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// Exit codes shared by all commands.
+const (
+	ExitOK = 0
+	// ExitError means the command could not run: bad flags, unreadable files.
+	ExitError = 1
+	// ExitFailed means the command ran and its check did not pass, so CI
+	// pipelines can tell a failing gate from a broken invocation.
+	ExitFailed = 2
+	// ExitWarning means the check passed with a warning: `budget --check`
+	// returns it while the budget is tight but not yet exceeded.
+	ExitWarning = 3
+)
+
+// Env carries the skill sources resolved by main and the streams commands
+// write to.
+type Env struct {
+	PluginsFile string
+	LocalDirs   []discovery.LocalSkillsDir
+	// StateFile is the skillex state file holding profiles.
+	StateFile string
+	// ProjectFile is the project's .claude/skillex.yaml, or empty outside a
+	// project. The file itself may not exist.
+	ProjectFile string
+	// ProjectDir is the project's root, the git root when there is one, or
+	// empty outside a project.
+	ProjectDir string
+	// Exclude hides skills whose ID or directory matches a glob.
+	Exclude []string
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(env Env, args []string) int
+}
+
+func commands() []command {
+	return []command{
+		{"list", "List skills, optionally filtered with a query", runList},
+		{"diff", "Compare a skill with its previous version or another skill", runDiff},
+		{"report", "Export the skill inventory as a Markdown or HTML document", runReport},
+		{"plugin", "List, install and uninstall plugins from local directories and marketplace clones", runPlugin},
+		{"check", "Lint a plugin source tree and measure its budget before install", runCheck},
+		{"budget", "Show the description budget and gate CI on it", runBudget},
+		{"audit", "Scan skills for risky tool grants, scripts and hidden content", runAudit},
+		{"profile", "Save, apply and compare named sets of enabled skills", runProfile},
+		{"sync", "Enable and disable skills to match the project's .claude/skillex.yaml", runSync},
+		{"backend", "Show or choose how skills are disabled", runBackend},
+		{"reapply", "Disable again the skills a plugin update re-enabled", runReapply},
+		{"history", "List recorded changes, newest first", runHistory},
+		{"undo", "Revert the most recent change", runUndo},
+		{"redo", "Re-apply the most recently undone change", runRedo},
+		{"help", "Show this help", runHelp},
+	}
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit
+// code.
+func Run(args []string, env Env) int {
+	if len(args) == 0 {
+		return runHelp(env, nil)
+	}
+	for _, c := range commands() {
+		if c.name == args[0] {
+			return c.run(env, args[1:])
+		}
+	}
+	if args[0] == "-h" || args[0] == "--help" {
+		return runHelp(env, nil)
+	}
+	fmt.Fprintf(env.Stderr, "skillex: unknown command %q\n\n", args[0])
+	printUsage(env.Stderr)
+	return ExitError
+}
+
+func runHelp(env Env, _ []string) int {
+	printUsage(env.Stdout)
+	return ExitOK
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: skillex [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command, skillex opens the interactive skill browser.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags, given before the command:")
+	fmt.Fprintln(w, "  --claude-dir DIR  Claude config directory (default $CLAUDE_CONFIG_DIR or ~/.claude)")
+	fmt.Fprintln(w, "  --root DIR        Additional folder of skills; repeatable")
+	fmt.Fprintln(w, "  --exclude GLOB    Hide skills whose ID or directory matches; repeatable")
+	fmt.Fprintln(w, "  --nested          Also list .claude/skills folders anywhere in the git repository")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'skillex <command> -h' for command flags.")
+}
+
+// newFlagSet returns a FlagSet that reports errors instead of exiting and
+// prints usage to stderr.
+func newFlagSet(env Env, name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.Stderr, "Usage: skillex %s %s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args and maps flag errors to an exit code. ok is false
+// when the command should return code immediately.
+func parseFlags(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK, false
+		}
+		return ExitError, false
+	}
+	return ExitOK, true
+}
+
+// discoverSkills runs discovery and lets the backend mark the skills it
+// disabled or saw reverted by plugin updates. It also returns the skills that
+// failed to parse.
+func discoverSkills(env Env) ([]discovery.Skill, []discovery.Failure, error) {
+	cache := discovery.OpenCache(config.FilesOf(env.StateFile).DiscoveryCache())
+	skills, failures, err := discovery.DiscoverCached(env.PluginsFile, env.LocalDirs, cache)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cache.Save(); err != nil {
+		fmt.Fprintf(env.Stderr, "skillex: warning: %v\n", err)
+	}
+	skills = config.Filter(skills, env.Exclude)
+	b, err := backend.Load(env.StateFile, config.FilesOf(env.StateFile).ClaudeSettings())
+	if err != nil {
+		return nil, nil, err
+	}
+	b.Mark(skills)
+	return skills, failures, nil
+}
+
+// loadSkills runs discoverSkills and narrows the result to the skills named
+// in names, matched by name or by "plugin:name". An empty names keeps all
+// skills.
+func loadSkills(env Env, names []string) ([]discovery.Skill, error) {
+	skills, _, err := discoverSkills(env)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return skills, nil
+	}
+
+	var selected []discovery.Skill
+	matched := make(map[string]bool)
+	for _, s := range skills {
+		for _, n := range names {
+			if s.Name == n || s.ID() == n {
+				selected = append(selected, s)
+				matched[n] = true
+				break
+			}
+		}
+	}
+	var missing []string
+	for _, n := range names {
+		if !matched[n] {
+			missing = append(missing, n)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no skill named %s", strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/muesli/termenv"
+	"github.com/smauermann/skillex/internal/config"
+	"github.com/smauermann/skillex/internal/diff"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/snapshot"
+)
+
+// captureSnapshots records the skills' current text for later diffs. Of the
+// commands, only diff calls it; the others leave the cache alone. The cache
+// is an aid, so failing to write it only warns.
+func captureSnapshots(env Env, skills []discovery.Skill) {
+	if env.StateFile == "" {
+		return
+	}
+	if _, err := snapshot.Update(config.FilesOf(env.StateFile).Snapshots(), skills); err != nil {
+		fmt.Fprintf(env.Stderr, "skillex: warning: %v\n", err)
+	}
+}
+
+func runDiff(env Env, args []string) int {
+	fs := newFlagSet(env, "diff", "[flags] <skill> [<other-skill>]\n\nWith one skill, compares it against the version seen before its last change.\nWith two, compares the first against the second.")
+	context := fs.Int("U", 3, "show `n` lines of context around changes")
+	color := fs.String("color", "auto", "colorize output: auto, always or never")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return ExitError
+	}
+	var opts []termenv.OutputOption
+	switch *color {
+	case "auto":
+	case "always":
+		opts = append(opts, termenv.WithProfile(termenv.ANSI))
+	case "never":
+		opts = append(opts, termenv.WithProfile(termenv.Ascii))
+	default:
+		fmt.Fprintf(env.Stderr, "skillex diff: --color must be auto, always or never, got %q\n", *color)
+		return ExitError
+	}
+
+	skills, err := loadSkills(env, nil)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex diff: %v\n", err)
+		return ExitError
+	}
+	captureSnapshots(env, skills)
+	a, err := findSkill(skills, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex diff: %v\n", err)
+		return ExitError
+	}
+
+	var oldLabel, newLabel, oldText, newText string
+	if fs.NArg() == 2 {
+		b, err := findSkill(skills, fs.Arg(1))
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "skillex diff: %v\n", err)
+			return ExitError
+		}
+		oldLabel, oldText = a.FilePath, diff.Document(a.Frontmatter, a.Content)
+		newLabel, newText = b.FilePath, diff.Document(b.Frontmatter, b.Content)
+	} else {
+		cache, err := snapshot.Load(config.FilesOf(env.StateFile).Snapshots())
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "skillex diff: %v\n", err)
+			return ExitError
+		}
+		prev, ok := cache.Previous(a.ID())
+		if !ok {
+			fmt.Fprintf(env.Stdout, "No earlier version of %s has been seen.\n", a.ID())
+			return ExitOK
+		}
+		oldLabel = fmt.Sprintf("%s (seen %s)", a.ID(), prev.SeenAt.Local().Format("2006-01-02 15:04"))
+		oldText = diff.Document(prev.Frontmatter, prev.Content)
+		newLabel, newText = a.FilePath, diff.Document(a.Frontmatter, a.Content)
+	}
+
+	lines := diff.Lines(oldText, newText)
+	if !diff.Changed(lines) {
+		fmt.Fprintln(env.Stdout, "No differences.")
+		return ExitOK
+	}
+	out := termenv.NewOutput(env.Stdout, opts...)
+	fmt.Fprintln(env.Stdout, out.String("--- "+oldLabel).Bold())
+	fmt.Fprintln(env.Stdout, out.String("+++ "+newLabel).Bold())
+	for _, h := range diff.Hunks(lines, *context) {
+		header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+		fmt.Fprintln(env.Stdout, out.String(header).Foreground(out.Color("6")))
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case diff.Delete:
+				fmt.Fprintln(env.Stdout, out.String("-"+l.Text).Foreground(out.Color("1")))
+			case diff.Insert:
+				fmt.Fprintln(env.Stdout, out.String("+"+l.Text).Foreground(out.Color("2")))
+			default:
+				fmt.Fprintln(env.Stdout, " "+l.Text)
+			}
+		}
+	}
+	return ExitOK
+}
+
+// findSkill returns the one skill named name or "plugin:name".
+func findSkill(skills []discovery.Skill, name string) (discovery.Skill, error) {
+	var found []discovery.Skill
+	for _, s := range skills {
+		if s.ID() == name {
+			return s, nil
+		}
+		if s.Name == name {
+			found = append(found, s)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return discovery.Skill{}, fmt.Errorf("no skill named %s", name)
+	case 1:
+		return found[0], nil
+	}
+	ids := make([]string, len(found))
+	for i, s := range found {
+		ids[i] = s.ID()
+	}
+	return discovery.Skill{}, fmt.Errorf("%s is ambiguous, use one of %s", name, strings.Join(ids, ", "))
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/smauermann/skillex/internal/config"
+	"github.com/smauermann/skillex/internal/journal"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// updateState applies fn to the state file under its lock and journals the
+// change under summary, so edits such as saving a profile can be undone.
+func updateState(env Env, summary string, fn func(*state.State) error) error {
+	before, after, err := state.Update(env.StateFile, fn)
+	if err != nil {
+		return err
+	}
+
+	j, err := journal.Open(config.FilesOf(env.StateFile).Journal())
+	if err != nil {
+		return err
+	}
+	return j.Record(summary, journal.Write(env.StateFile, before, after))
+}
+
+func runHistory(env Env, args []string) int {
+	fs := newFlagSet(env, "history", "[flags]")
+	limit := fs.Int("n", 20, "show at most `n` entries; 0 shows all")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	j, err := journal.Open(config.FilesOf(env.StateFile).Journal())
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex history: %v\n", err)
+		return ExitError
+	}
+	if len(j.Entries) == 0 {
+		fmt.Fprintln(env.Stdout, "No recorded changes.")
+		return ExitOK
+	}
+
+	shown := 0
+	for i := len(j.Entries) - 1; i >= 0; i-- {
+		if *limit > 0 && shown == *limit {
+			break
+		}
+		e := j.Entries[i]
+		mark := " "
+		if i >= j.Applied {
+			mark = "u"
+		}
+		fmt.Fprintf(env.Stdout, "%s %s  %s (%d file(s))\n", mark, e.Time.Local().Format("2006-01-02 15:04:05"), e.Summary, len(e.Actions))
+		shown++
+	}
+	if j.Applied < len(j.Entries) {
+		fmt.Fprintln(env.Stdout, "\nu = undone; 'skillex redo' re-applies the newest one.")
+	}
+	return ExitOK
+}
+
+func runUndo(env Env, args []string) int {
+	return runJournalStep(env, "undo", args, (*journal.Journal).Undo, "Undid")
+}
+
+func runRedo(env Env, args []string) int {
+	return runJournalStep(env, "redo", args, (*journal.Journal).Redo, "Redid")
+}
+
+// runJournalStep implements undo and redo, which differ only in direction.
+func runJournalStep(env Env, name string, args []string, step func(*journal.Journal) (journal.Entry, error), done string) int {
+	fs := newFlagSet(env, name, "")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	j, err := journal.Open(config.FilesOf(env.StateFile).Journal())
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex %s: %v\n", name, err)
+		return ExitError
+	}
+	e, err := step(j)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex %s: %v\n", name, err)
+		return ExitError
+	}
+	fmt.Fprintf(env.Stdout, "%s: %s\n", done, e.Summary)
+	return ExitOK
+}
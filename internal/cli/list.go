@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/query"
+)
+
+func runList(env Env, args []string) int {
+	fs := newFlagSet(env, "list", "[flags]")
+	q := fs.String("query", "", "only list skills matching `query`, e.g. 'status:disabled desc:>300'; fields: "+strings.Join(query.Fields, ", "))
+	format := fs.String("format", "text", "output format: text or json")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(env.Stderr, "skillex list: unknown format %q\n", *format)
+		return ExitError
+	}
+
+	parsed, err := query.Parse(*q)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex list: invalid query: %v\n", err)
+		return ExitError
+	}
+	skills, err := loadSkills(env, nil)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex list: %v\n", err)
+		return ExitError
+	}
+
+	var matched []discovery.Skill
+	for _, s := range skills {
+		if parsed.Match(s) {
+			matched = append(matched, s)
+		}
+	}
+
+	if *format == "json" {
+		records := make([]discovery.Record, len(matched))
+		for i, s := range matched {
+			records[i] = s.Record()
+		}
+		enc := json.NewEncoder(env.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(records); err != nil {
+			fmt.Fprintf(env.Stderr, "skillex list: %v\n", err)
+			return ExitError
+		}
+		return ExitOK
+	}
+
+	for _, s := range matched {
+		status := "enabled"
+		switch {
+		case s.Category != "":
+			status = "not loaded"
+		case !s.Enabled:
+			status = "disabled"
+		}
+		fmt.Fprintf(env.Stdout, "%-10s  %-9s %5d  %s\n", status, s.ActivationStyle, len(s.Description), s.ID())
+	}
+	fmt.Fprintf(env.Stdout, "%d of %d skill(s)\n", len(matched), len(skills))
+	return ExitOK
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/config"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/install"
+	"github.com/smauermann/skillex/internal/journal"
+)
+
+const pluginUsage = `Usage: skillex plugin <subcommand> [flags]
+
+Subcommands:
+  list                              List installed plugins
+  install <plugin@marketplace>      Install a plugin from a local marketplace clone
+  install --path <dir>              Install the plugin in a local directory
+  uninstall <plugin[@marketplace]>  Remove a plugin from installed_plugins.json;
+                                    --scope picks one of several installations
+
+Plugins are registered where they are on disk; nothing is copied or deleted.
+The previous installed_plugins.json is kept as installed_plugins.json.bak,
+and skillex undo reverts the change. Start a new Claude Code session to
+apply it.
+`
+
+func runPlugin(env Env, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(env.Stderr, pluginUsage)
+		if len(args) == 0 {
+			return ExitError
+		}
+		return ExitOK
+	}
+
+	switch sub, rest := args[0], args[1:]; sub {
+	case "list":
+		return pluginList(env, rest)
+	case "install":
+		return pluginInstall(env, rest)
+	case "uninstall":
+		return pluginUninstall(env, rest)
+	default:
+		fmt.Fprintf(env.Stderr, "skillex plugin: unknown subcommand %q\n\n%s", sub, pluginUsage)
+		return ExitError
+	}
+}
+
+func pluginList(env Env, args []string) int {
+	if len(args) != 0 {
+		fmt.Fprint(env.Stderr, pluginUsage)
+		return ExitError
+	}
+	installed, err := discovery.ReadInstalled(env.PluginsFile)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex plugin: %v\n", err)
+		return ExitError
+	}
+	if len(installed.Plugins) == 0 {
+		fmt.Fprintln(env.Stdout, "No plugins installed.")
+		return ExitOK
+	}
+	keys := make([]string, 0, len(installed.Plugins))
+	for key := range installed.Plugins {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if len(installed.Plugins[key]) == 0 {
+			continue
+		}
+		inst := installed.Plugins[key][0]
+		fmt.Fprintf(env.Stdout, "%-40s %-12s %-7s %s\n", key, inst.Version, inst.Scope, inst.InstallPath)
+	}
+	return ExitOK
+}
+
+func pluginInstall(env Env, args []string) int {
+	fs := newFlagSet(env, "plugin install", "<plugin@marketplace> | --path dir [flags]")
+	path := fs.String("path", "", "install the plugin in this directory instead of one from a marketplace clone")
+	market := fs.String("marketplace", install.LocalMarketplace, "marketplace to name in the key of a plugin installed with --path")
+	scope := fs.String("scope", "user", "installation scope: "+strings.Join(install.Scopes, ", "))
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	opts := install.Options{Scope: *scope}
+	if *scope != "user" {
+		opts.ProjectPath = env.ProjectDir
+	}
+
+	dir := *path
+	switch {
+	case dir != "" && fs.NArg() == 0:
+		opts.Marketplace = *market
+	case dir == "" && fs.NArg() == 1:
+		name, marketName, ok := strings.Cut(fs.Arg(0), "@")
+		if !ok || name == "" || marketName == "" {
+			fmt.Fprintf(env.Stderr, "skillex plugin install: want plugin@marketplace, got %q\n", fs.Arg(0))
+			return ExitError
+		}
+		p, err := discovery.FindMarketplacePlugin(env.PluginsFile, name, marketName)
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "skillex plugin install: %v\n", err)
+			return ExitError
+		}
+		dir = p.Dir
+		opts.Name, opts.Marketplace, opts.Version = p.Name, marketName, p.Version
+	default:
+		fs.Usage()
+		return ExitError
+	}
+
+	c, err := install.Install(env.PluginsFile, dir, opts)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex plugin install: %v\n", err)
+		return ExitError
+	}
+	if err := applyPluginChange(env, c); err != nil {
+		fmt.Fprintf(env.Stderr, "skillex plugin install: %v\n", err)
+		return ExitError
+	}
+	fmt.Fprintf(env.Stdout, "Installed %s from %s. Start a new Claude Code session to load it.\n", c.Key, dir)
+	return ExitOK
+}
+
+func pluginUninstall(env Env, args []string) int {
+	fs := newFlagSet(env, "plugin uninstall", "<plugin[@marketplace]> [flags]")
+	scope := fs.String("scope", "", "remove only the entry in this scope: "+strings.Join(install.Scopes, ", ")+"; needed when the plugin is installed in several")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitError
+	}
+
+	var projectPath string
+	if *scope != "" && *scope != "user" {
+		projectPath = env.ProjectDir
+	}
+	c, err := install.Uninstall(env.PluginsFile, fs.Arg(0), *scope, projectPath)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex plugin uninstall: %v\n", err)
+		return ExitError
+	}
+	if err := applyPluginChange(env, c); err != nil {
+		fmt.Fprintf(env.Stderr, "skillex plugin uninstall: %v\n", err)
+		return ExitError
+	}
+	fmt.Fprintf(env.Stdout, "Uninstalled %s. Its files were left in place.\n", c.Key)
+	return ExitOK
+}
+
+// applyPluginChange writes c and journals it, so it can be undone.
+func applyPluginChange(env Env, c install.Change) error {
+	if err := install.Apply(env.PluginsFile, c); err != nil {
+		return err
+	}
+	j, err := journal.Open(config.FilesOf(env.StateFile).Journal())
+	if err != nil {
+		return err
+	}
+	return j.Record(c.Summary, journal.Write(env.PluginsFile, c.Before, c.After))
+}
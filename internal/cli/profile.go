@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/smauermann/skillex/internal/backend"
+	"github.com/smauermann/skillex/internal/config"
+	"github.com/smauermann/skillex/internal/profile"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+const profileUsage = `Usage: skillex profile <subcommand> [name]
+
+Subcommands:
+  list           List saved profiles
+  save <name>    Save the current enabled/disabled state as a profile
+  apply <name>   Enable and disable skills to match a profile
+  diff <name>    Show what applying a profile would change
+  delete <name>  Delete a saved profile
+`
+
+func runProfile(env Env, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(env.Stderr, profileUsage)
+		if len(args) == 0 {
+			return ExitError
+		}
+		return ExitOK
+	}
+
+	sub, rest := args[0], args[1:]
+	if sub == "list" {
+		if len(rest) != 0 {
+			fmt.Fprint(env.Stderr, profileUsage)
+			return ExitError
+		}
+		return profileList(env)
+	}
+	if len(rest) != 1 {
+		fmt.Fprint(env.Stderr, profileUsage)
+		return ExitError
+	}
+
+	name := rest[0]
+	switch sub {
+	case "save":
+		return profileSave(env, name)
+	case "apply":
+		return profileApply(env, name, false)
+	case "diff":
+		return profileApply(env, name, true)
+	case "delete":
+		return profileDelete(env, name)
+	}
+	fmt.Fprintf(env.Stderr, "skillex profile: unknown subcommand %q\n\n%s", sub, profileUsage)
+	return ExitError
+}
+
+func profileList(env Env) int {
+	st, err := state.Load(env.StateFile)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex profile: %v\n", err)
+		return ExitError
+	}
+	if len(st.Profiles) == 0 {
+		fmt.Fprintln(env.Stdout, "No saved profiles. Create one with 'skillex profile save <name>'.")
+		return ExitOK
+	}
+
+	names := make([]string, 0, len(st.Profiles))
+	for name := range st.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := st.Profiles[name]
+		enabled := 0
+		for _, on := range p.Skills {
+			if on {
+				enabled++
+			}
+		}
+		fmt.Fprintf(env.Stdout, "%-20s %d/%d enabled  saved %s\n", name, enabled, len(p.Skills), p.SavedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return ExitOK
+}
+
+func profileSave(env Env, name string) int {
+	skills, err := loadSkills(env, nil)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex profile: %v\n", err)
+		return ExitError
+	}
+
+	p := profile.Capture(skills)
+	err = updateState(env, fmt.Sprintf("save profile %q", name), func(st *state.State) error {
+		if st.Profiles == nil {
+			st.Profiles = make(map[string]state.Profile)
+		}
+		st.Profiles[name] = p
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex profile: %v\n", err)
+		return ExitError
+	}
+	fmt.Fprintf(env.Stdout, "Saved profile %q with %d skills.\n", name, len(p.Skills))
+	return ExitOK
+}
+
+// profileApply applies a saved profile, or only prints the plan when dryRun
+// is set.
+func profileApply(env Env, name string, dryRun bool) int {
+	st, err := state.Load(env.StateFile)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex profile: %v\n", err)
+		return ExitError
+	}
+	p, ok := st.Profiles[name]
+	if !ok {
+		fmt.Fprintf(env.Stderr, "skillex profile: no profile named %q\n", name)
+		return ExitError
+	}
+	skills, err := loadSkills(env, nil)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex profile: %v\n", err)
+		return ExitError
+	}
+	b, err := backend.Load(env.StateFile, config.FilesOf(env.StateFile).ClaudeSettings())
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex profile: %v\n", err)
+		return ExitError
+	}
+
+	plan := profile.Diff(skills, p.Skills)
+	if !dryRun {
+		err := profile.ApplyRecorded(b, skills, plan.Changes, config.FilesOf(env.StateFile).Journal(), fmt.Sprintf("apply profile %q", name))
+		switch {
+		case errors.Is(err, profile.ErrNotJournaled):
+			printPlan(env, plan, false)
+			fmt.Fprintf(env.Stderr, "skillex profile: applied %q: %v\n", name, err)
+			return ExitError
+		case err != nil:
+			fmt.Fprintf(env.Stderr, "skillex profile: applying %q failed, no changes made: %v\n", name, err)
+			return ExitError
+		}
+	}
+	printPlan(env, plan, dryRun)
+	return ExitOK
+}
+
+// printPlan reports the changes of a plan, phrased as done or pending.
+func printPlan(env Env, plan profile.Plan, pending bool) {
+	for _, c := range plan.Changes {
+		fmt.Fprintln(env.Stdout, c)
+	}
+	for _, id := range plan.Missing {
+		fmt.Fprintf(env.Stdout, "missing %s (not installed)\n", id)
+	}
+
+	enabled, disabled := profile.Summary(plan.Changes)
+	switch {
+	case len(plan.Changes) == 0:
+		fmt.Fprintln(env.Stdout, "Already up to date.")
+	case pending:
+		fmt.Fprintf(env.Stdout, "Would enable %d and disable %d skill(s).\n", enabled, disabled)
+	default:
+		fmt.Fprintf(env.Stdout, "Enabled %d and disabled %d skill(s). Start a new Claude session to apply.\n", enabled, disabled)
+	}
+}
+
+func profileDelete(env Env, name string) int {
+	err := updateState(env, fmt.Sprintf("delete profile %q", name), func(st *state.State) error {
+		if _, ok := st.Profiles[name]; !ok {
+			return fmt.Errorf("no profile named %q", name)
+		}
+		delete(st.Profiles, name)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex profile: %v\n", err)
+		return ExitError
+	}
+	fmt.Fprintf(env.Stdout, "Deleted profile %q.\n", name)
+	return ExitOK
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/smauermann/skillex/internal/report"
+)
+
+func runReport(env Env, args []string) int {
+	fs := newFlagSet(env, "report", "[flags]")
+	format := fs.String("format", "", "output format: "+strings.Join(report.Formats, " or ")+"; defaults to the -o extension, else md")
+	out := fs.String("o", "", "write the report to `file` instead of stdout")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *format == "" {
+		*format = "md"
+		if ext := strings.TrimPrefix(filepath.Ext(*out), "."); ext == "html" || ext == "htm" {
+			*format = "html"
+		}
+	}
+
+	skills, failures, err := discoverSkills(env)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex report: %v\n", err)
+		return ExitError
+	}
+
+	var buf bytes.Buffer
+	inv := report.Inventory{GeneratedAt: time.Now(), Skills: skills, Failures: failures}
+	if err := report.Write(&buf, *format, inv); err != nil {
+		fmt.Fprintf(env.Stderr, "skillex report: %v\n", err)
+		return ExitError
+	}
+	if *out == "" {
+		env.Stdout.Write(buf.Bytes())
+		return ExitOK
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(env.Stderr, "skillex report: %v\n", err)
+		return ExitError
+	}
+	fmt.Fprintf(env.Stdout, "Wrote %s report of %d skill(s) to %s\n", *format, len(skills), *out)
+	return ExitOK
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/smauermann/skillex/internal/backend"
+	"github.com/smauermann/skillex/internal/config"
+	"github.com/smauermann/skillex/internal/profile"
+)
+
+func runSync(env Env, args []string) int {
+	fs := newFlagSet(env, "sync", "[flags]")
+	check := fs.Bool("check", false, "only report drift; exit with code 2 if skills differ from the project file")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if env.ProjectFile == "" {
+		fmt.Fprintln(env.Stderr, "skillex sync: not inside a project directory")
+		return ExitError
+	}
+	project, err := profile.LoadProject(env.ProjectFile)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex sync: %v\n", err)
+		return ExitError
+	}
+	if project == nil {
+		fmt.Fprintf(env.Stderr, "skillex sync: no %s in this project\n", profile.ProjectFileName)
+		return ExitError
+	}
+
+	skills, err := loadSkills(env, nil)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex sync: %v\n", err)
+		return ExitError
+	}
+	b, err := backend.Load(env.StateFile, config.FilesOf(env.StateFile).ClaudeSettings())
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "skillex sync: %v\n", err)
+		return ExitError
+	}
+
+	plan := project.Plan(skills)
+	if !*check {
+		err := profile.ApplyRecorded(b, skills, plan.Changes, config.FilesOf(env.StateFile).Journal(), "sync with "+profile.ProjectFileName)
+		switch {
+		case errors.Is(err, profile.ErrNotJournaled):
+			printPlan(env, plan, false)
+			fmt.Fprintf(env.Stderr, "skillex sync: %v\n", err)
+			return ExitError
+		case err != nil:
+			fmt.Fprintf(env.Stderr, "skillex sync: failed, no changes made: %v\n", err)
+			return ExitError
+		}
+	}
+	printPlan(env, plan, *check)
+
+	if *check && len(plan.Changes) > 0 {
+		return ExitFailed
+	}
+	return ExitOK
+}
@@ -0,0 +1,261 @@
+// Package config resolves where skillex looks for skills and keeps its
+// state: the Claude config directory, extra skill roots and exclusion globs,
+// from flags, the environment and skillex's config file.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/state"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVar is the environment variable Claude Code reads its config
+// directory from.
+const EnvVar = "CLAUDE_CONFIG_DIR"
+
+// FileName is the config file's name inside the skillex directory.
+const FileName = "config.yaml"
+
+// Options are the settings given on the command line. They add to the
+// config file; ClaudeDir overrides EnvVar.
+type Options struct {
+	ClaudeDir string
+	Roots     []string
+	Exclude   []string
+	// Nested, when set, overrides the config file's nested: true also
+	// lists .claude/skills folders anywhere in the repository.
+	Nested *bool
+}
+
+// File is the content of the config file.
+type File struct {
+	// Roots are extra folders of skills, each laid out like
+	// ~/.claude/skills.
+	Roots []Root `yaml:"roots"`
+	// Exclude hides skills whose ID or directory matches a glob.
+	Exclude []string `yaml:"exclude"`
+	// Nested lists the .claude/skills folders of every package in a
+	// monorepo, not just those from the working directory up.
+	Nested bool `yaml:"nested"`
+}
+
+// Root is a folder of skills and the name its skills are listed under.
+type Root struct {
+	Path string `yaml:"path"`
+	Name string `yaml:"name"`
+}
+
+// UnmarshalYAML accepts a bare path as well as a mapping.
+func (r *Root) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		r.Path = value.Value
+		return nil
+	}
+	type plain Root
+	return value.Decode((*plain)(r))
+}
+
+// Config is the resolved configuration.
+type Config struct {
+	// ClaudeDir is Claude Code's config directory, ~/.claude by default.
+	ClaudeDir   string
+	PluginsFile string
+	// StateFile is skillex's state file; the config file and skillex's
+	// other files live next to it.
+	StateFile string
+	// LocalDirs are the skill folders outside plugins that exist: the one
+	// in ClaudeDir, the project's from the git root down, and the extra
+	// roots.
+	LocalDirs []discovery.LocalSkillsDir
+	// ProjectFile is the closest .claude/skillex.yaml up to the git root,
+	// or the working directory's, which may not exist. It is empty outside a
+	// project.
+	ProjectFile string
+	// ProjectDir is the project's root: the git root above the working
+	// directory, or the working directory itself outside a repository. It
+	// is empty outside a project.
+	ProjectDir string
+	Exclude    []string
+}
+
+// Load resolves the configuration for a process running in workDir with
+// home as the user's home directory.
+func Load(opts Options, home, workDir string) (*Config, error) {
+	claudeDir := opts.ClaudeDir
+	if claudeDir == "" {
+		claudeDir = os.Getenv(EnvVar)
+	}
+	if claudeDir == "" {
+		claudeDir = filepath.Join(home, ".claude")
+	}
+	claudeDir = expandHome(claudeDir, home)
+
+	c := &Config{
+		ClaudeDir:   claudeDir,
+		PluginsFile: filepath.Join(claudeDir, "plugins", "installed_plugins.json"),
+		StateFile:   filepath.Join(claudeDir, "skillex", state.FileName),
+	}
+
+	configFile := FilesOf(c.StateFile).Config()
+	file, err := LoadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir := filepath.Join(claudeDir, "skills"); isDir(dir) {
+		c.LocalDirs = append(c.LocalDirs, discovery.LocalSkillsDir{Path: dir, Name: "local"})
+	}
+	if workDir != "" && workDir != home {
+		nested := file.Nested
+		if opts.Nested != nil {
+			nested = *opts.Nested
+		}
+		project, projectFile := projectSkills(workDir, home, nested)
+		for _, d := range project {
+			if d.Path != filepath.Join(claudeDir, "skills") {
+				c.LocalDirs = append(c.LocalDirs, d)
+			}
+		}
+		c.ProjectFile = projectFile
+		c.ProjectDir = projectRoot(workDir, home)
+	}
+
+	// Relative roots are relative to where they were given: the config
+	// file's folder, or the working directory for --root.
+	type root struct {
+		Root
+		base string
+	}
+	var roots []root
+	for _, r := range file.Roots {
+		roots = append(roots, root{r, filepath.Dir(configFile)})
+	}
+	for _, p := range opts.Roots {
+		roots = append(roots, root{Root{Path: p}, workDir})
+	}
+	for _, r := range roots {
+		path := expandHome(r.Path, home)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.base, path)
+		}
+		if !isDir(path) {
+			return nil, fmt.Errorf("skill root %s is not a directory", r.Path)
+		}
+		name := r.Name
+		if name == "" {
+			name = filepath.Base(path)
+		}
+		c.LocalDirs = append(c.LocalDirs, discovery.LocalSkillsDir{Path: path, Name: name})
+	}
+
+	c.Exclude = append(append([]string(nil), file.Exclude...), opts.Exclude...)
+	for _, pattern := range c.Exclude {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return c, nil
+}
+
+// LoadFile reads the config file at path. A missing file yields an empty
+// config.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Excluded reports whether a pattern matches the skill's ID or directory.
+func Excluded(s discovery.Skill, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, s.ID()); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(p, s.Dir()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the skills no pattern excludes.
+func Filter(skills []discovery.Skill, patterns []string) []discovery.Skill {
+	if len(patterns) == 0 {
+		return skills
+	}
+	var kept []discovery.Skill
+	for _, s := range skills {
+		if !Excluded(s, patterns) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// ParseArgs splits the global flags that precede the command from args.
+// Flags take their value as the next argument or after "=". The boolean
+// --nested takes a value only after "=".
+func ParseArgs(args []string) (opts Options, rest []string, err error) {
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if name == "nested" && strings.HasPrefix(args[0], "-") {
+			nested := true
+			if hasValue {
+				if nested, err = strconv.ParseBool(value); err != nil {
+					return opts, nil, fmt.Errorf("flag --nested: invalid value %q", value)
+				}
+			}
+			opts.Nested = &nested
+			args = args[1:]
+			continue
+		}
+		target := map[string]*[]string{"root": &opts.Roots, "exclude": &opts.Exclude}[name]
+		if !strings.HasPrefix(args[0], "-") || (name != "claude-dir" && target == nil) {
+			break
+		}
+		if !hasValue {
+			if len(args) < 2 {
+				return opts, nil, fmt.Errorf("flag --%s needs a value", name)
+			}
+			value, args = args[1], args[1:]
+		}
+		args = args[1:]
+		if target != nil {
+			*target = append(*target, value)
+		} else {
+			opts.ClaudeDir = value
+		}
+	}
+	return opts, args, nil
+}
+
+// expandHome replaces a leading ~ with home.
+func expandHome(path, home string) string {
+	if path == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return path
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
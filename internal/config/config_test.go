@@ -0,0 +1,231 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+func mkdir(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadClaudeDir(t *testing.T) {
+	home := t.TempDir()
+	env := t.TempDir()
+	flag := t.TempDir()
+
+	t.Setenv(EnvVar, "")
+	cfg, err := Load(Options{}, home, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".claude"); cfg.ClaudeDir != want {
+		t.Errorf("default ClaudeDir = %q, want %q", cfg.ClaudeDir, want)
+	}
+	if cfg.ProjectFile != "" {
+		t.Errorf("ProjectFile = %q in the home directory, want empty", cfg.ProjectFile)
+	}
+
+	t.Setenv(EnvVar, env)
+	cfg, err = Load(Options{}, home, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClaudeDir != env {
+		t.Errorf("ClaudeDir = %q, want %s %q", cfg.ClaudeDir, EnvVar, env)
+	}
+	if want := filepath.Join(env, "plugins", "installed_plugins.json"); cfg.PluginsFile != want {
+		t.Errorf("PluginsFile = %q, want %q", cfg.PluginsFile, want)
+	}
+
+	cfg, err = Load(Options{ClaudeDir: flag}, home, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClaudeDir != flag {
+		t.Errorf("ClaudeDir = %q, want the flag's %q", cfg.ClaudeDir, flag)
+	}
+}
+
+func TestLoadRootsAndExclude(t *testing.T) {
+	home := t.TempDir()
+	work := filepath.Join(home, "project")
+	claudeDir := filepath.Join(home, ".claude")
+	mkdir(t, filepath.Join(claudeDir, "skills"))
+	mkdir(t, filepath.Join(work, ".claude", "skills"))
+	mkdir(t, filepath.Join(home, "team-skills"))
+	mkdir(t, filepath.Join(work, "vendor-skills"))
+	mkdir(t, filepath.Join(claudeDir, "skillex", "shared"))
+	// A folder of the same name in the working directory must not be
+	// picked for the config file's relative root.
+	mkdir(t, filepath.Join(work, "shared"))
+	if err := os.WriteFile(filepath.Join(claudeDir, "skillex", FileName), []byte(`
+roots:
+  - path: ~/team-skills
+    name: team
+  - shared
+exclude:
+  - "experimental:*"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvVar, "")
+
+	cfg, err := Load(Options{Roots: []string{"vendor-skills"}, Exclude: []string{"*:draft-*"}}, home, work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []discovery.LocalSkillsDir{
+		{Path: filepath.Join(claudeDir, "skills"), Name: "local"},
+		{Path: filepath.Join(work, ".claude", "skills"), Name: "project"},
+		{Path: filepath.Join(home, "team-skills"), Name: "team"},
+		{Path: filepath.Join(claudeDir, "skillex", "shared"), Name: "shared"},
+		{Path: filepath.Join(work, "vendor-skills"), Name: "vendor-skills"},
+	}
+	if !reflect.DeepEqual(cfg.LocalDirs, want) {
+		t.Errorf("LocalDirs = %+v, want %+v", cfg.LocalDirs, want)
+	}
+	if want := []string{"experimental:*", "*:draft-*"}; !reflect.DeepEqual(cfg.Exclude, want) {
+		t.Errorf("Exclude = %q, want %q", cfg.Exclude, want)
+	}
+
+	if _, err := Load(Options{Roots: []string{"missing"}}, home, work); err == nil {
+		t.Error("Load() with a missing root succeeded, want an error")
+	}
+	if _, err := Load(Options{Exclude: []string{"["}}, home, work); err == nil {
+		t.Error("Load() with a malformed pattern succeeded, want an error")
+	}
+}
+
+func TestFilesOf(t *testing.T) {
+	files := FilesOf(filepath.Join("home", ".claude", "skillex", "state.json"))
+	dir := filepath.Join("home", ".claude", "skillex")
+	if got := files.Journal(); got != filepath.Join(dir, "journal.json") {
+		t.Errorf("Journal() = %q", got)
+	}
+	if got := files.DiscoveryCache(); got != filepath.Join(dir, "discovery-cache.gob") {
+		t.Errorf("DiscoveryCache() = %q", got)
+	}
+	if got := files.ClaudeSettings(); got != filepath.Join("home", ".claude", "settings.json") {
+		t.Errorf("ClaudeSettings() = %q", got)
+	}
+	if got := FilesOf("").DiscoveryCache(); got != "" {
+		t.Errorf("expected no paths without a state file, got %q", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	skills := []discovery.Skill{
+		{Name: "draft-notes", Plugin: "local", FilePath: "/skills/draft-notes/SKILL.md"},
+		{Name: "review", Plugin: "team", FilePath: "/team/review/SKILL.md"},
+		{Name: "plan", Plugin: "superpowers", FilePath: "/cache/plan/SKILL.md"},
+	}
+	got := Filter(skills, []string{"*:draft-*", "/team/*"})
+	if len(got) != 1 || got[0].Name != "plan" {
+		t.Errorf("Filter() = %+v, want only plan", got)
+	}
+	if got := Filter(skills, nil); len(got) != len(skills) {
+		t.Errorf("Filter() without patterns kept %d skill(s), want %d", len(got), len(skills))
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	opts, rest, err := ParseArgs([]string{"--claude-dir", "/c", "--nested", "--root=/a", "-root", "/b", "--exclude", "x:*", "list", "--root", "y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested := true
+	want := Options{ClaudeDir: "/c", Nested: &nested, Roots: []string{"/a", "/b"}, Exclude: []string{"x:*"}}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("opts = %+v, want %+v", opts, want)
+	}
+	if !reflect.DeepEqual(rest, []string{"list", "--root", "y"}) {
+		t.Errorf("rest = %q, want the command and its flags", rest)
+	}
+
+	if _, rest, _ := ParseArgs([]string{"-h"}); len(rest) != 1 {
+		t.Errorf("ParseArgs(-h) consumed the flag, want it left for the command line")
+	}
+	if _, _, err := ParseArgs([]string{"--claude-dir"}); err == nil {
+		t.Error("ParseArgs() without a value succeeded, want an error")
+	}
+
+	for arg, want := range map[string]bool{"--nested=true": true, "--nested=false": false, "-nested=1": true} {
+		opts, rest, err := ParseArgs([]string{arg, "list"})
+		if err != nil || opts.Nested == nil || *opts.Nested != want || !reflect.DeepEqual(rest, []string{"list"}) {
+			t.Errorf("ParseArgs(%s) = %+v, %q, %v; want Nested %v", arg, opts, rest, err, want)
+		}
+	}
+	if _, _, err := ParseArgs([]string{"--nested=maybe"}); err == nil {
+		t.Error("ParseArgs(--nested=maybe) succeeded, want an error")
+	}
+}
+
+func TestProjectSkillsMonorepo(t *testing.T) {
+	home := t.TempDir()
+	repo := filepath.Join(home, "mono")
+	api := filepath.Join(repo, "packages", "api")
+	mkdir(t, filepath.Join(repo, ".git"))
+	mkdir(t, filepath.Join(repo, ".claude", "skills"))
+	mkdir(t, filepath.Join(repo, "packages", ".claude", "skills"))
+	mkdir(t, filepath.Join(api, ".claude", "skills"))
+	mkdir(t, filepath.Join(api, "src"))
+	mkdir(t, filepath.Join(repo, "packages", "web", ".claude", "skills"))
+	mkdir(t, filepath.Join(repo, "node_modules", "dep", ".claude", "skills"))
+	if err := os.WriteFile(filepath.Join(repo, ".claude", "skillex.yaml"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvVar, "")
+
+	names := func(dirs []discovery.LocalSkillsDir) []string {
+		var got []string
+		for _, d := range dirs {
+			got = append(got, d.Name)
+		}
+		return got
+	}
+
+	cfg, err := Load(Options{}, home, filepath.Join(api, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"mono", "mono/packages", "mono/packages/api"}; !reflect.DeepEqual(names(cfg.LocalDirs), want) {
+		t.Errorf("walking up, sources = %q, want %q", names(cfg.LocalDirs), want)
+	}
+	if want := filepath.Join(repo, ".claude", "skillex.yaml"); cfg.ProjectFile != want {
+		t.Errorf("ProjectFile = %q, want the repository's %q", cfg.ProjectFile, want)
+	}
+	if cfg.ProjectDir != repo {
+		t.Errorf("ProjectDir = %q, want the git root %q", cfg.ProjectDir, repo)
+	}
+
+	nested := true
+	cfg, err = Load(Options{Nested: &nested}, home, filepath.Join(api, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"mono", "mono/packages", "mono/packages/api", "mono/packages/web"}; !reflect.DeepEqual(names(cfg.LocalDirs), want) {
+		t.Errorf("with nested, sources = %q, want %q", names(cfg.LocalDirs), want)
+	}
+
+	// --nested=false turns off nested: true from the config file.
+	mkdir(t, filepath.Join(home, ".claude", "skillex"))
+	if err := os.WriteFile(filepath.Join(home, ".claude", "skillex", FileName), []byte("nested: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	nested = false
+	cfg, err = Load(Options{Nested: &nested}, home, filepath.Join(api, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"mono", "mono/packages", "mono/packages/api"}; !reflect.DeepEqual(names(cfg.LocalDirs), want) {
+		t.Errorf("with --nested=false, sources = %q, want %q", names(cfg.LocalDirs), want)
+	}
+}
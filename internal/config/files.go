@@ -0,0 +1,60 @@
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/fingerprint"
+	"github.com/smauermann/skillex/internal/journal"
+	"github.com/smauermann/skillex/internal/snapshot"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// Files locates skillex's own files. They all live in one directory, next to
+// the state file. The zero Files has no directory and every path is empty.
+type Files struct {
+	Dir string
+}
+
+// FilesOf returns the files kept next to the state file at stateFile, or the
+// zero Files when stateFile is empty.
+func FilesOf(stateFile string) Files {
+	if stateFile == "" {
+		return Files{}
+	}
+	return Files{Dir: filepath.Dir(stateFile)}
+}
+
+func (f Files) path(name string) string {
+	if f.Dir == "" {
+		return ""
+	}
+	return filepath.Join(f.Dir, name)
+}
+
+// ClaudeSettings is Claude Code's user settings file, in the Claude config
+// directory that holds skillex's own.
+func (f Files) ClaudeSettings() string {
+	if f.Dir == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(f.Dir), "settings.json")
+}
+
+// Config is skillex's config file.
+func (f Files) Config() string { return f.path(FileName) }
+
+// Journal is the change journal that undo and redo replay.
+func (f Files) Journal() string { return f.path(journal.FileName) }
+
+// Snapshots is the cache of previously seen skill versions.
+func (f Files) Snapshots() string { return f.path(snapshot.FileName) }
+
+// Fingerprints records the skills seen at the previous launch.
+func (f Files) Fingerprints() string { return f.path(fingerprint.FileName) }
+
+// UI holds the TUI's view preferences.
+func (f Files) UI() string { return f.path(state.UIFileName) }
+
+// DiscoveryCache is the cache of parsed skill files and bundled file types.
+func (f Files) DiscoveryCache() string { return f.path(discovery.CacheFileName) }
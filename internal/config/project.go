@@ -0,0 +1,128 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/profile"
+)
+
+// skippedDirs are never searched for nested .claude/skills folders.
+var skippedDirs = map[string]bool{"node_modules": true, "vendor": true}
+
+// gitRoot returns the closest directory from dir upwards that contains
+// .git, or "" outside a repository.
+func gitRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// projectRoot returns the root of the project workDir belongs to: its git
+// root, or workDir outside a repository or in one rooted at home.
+func projectRoot(workDir, home string) string {
+	if root := gitRoot(workDir); root != "" && root != home {
+		return root
+	}
+	return workDir
+}
+
+// projectDirs returns the directories from root down to workDir. Outside a
+// repository, root is "" and only workDir is returned.
+func projectDirs(root, workDir string) []string {
+	if root == "" {
+		return []string{workDir}
+	}
+	var dirs []string
+	for dir := workDir; ; dir = filepath.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+		if dir == root || dir == filepath.Dir(dir) {
+			return dirs
+		}
+	}
+}
+
+// nestedDirs returns the directories below root holding a .claude/skills
+// folder, in walk order. Hidden directories and dependency folders are
+// skipped.
+func nestedDirs(root string) []string {
+	var dirs []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()]) {
+			return filepath.SkipDir
+		}
+		if path != root && isDir(filepath.Join(path, ".claude", "skills")) {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs
+}
+
+// projectLabel names the skills of dir's .claude/skills: the repository's
+// folder name, followed by dir's path inside it.
+func projectLabel(root, dir string) string {
+	if root == "" {
+		return filepath.Base(dir)
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return filepath.Base(root)
+	}
+	return filepath.Base(root) + "/" + filepath.ToSlash(rel)
+}
+
+// projectSkills returns the project skill folders seen from workDir, like
+// Claude Code resolves project settings: every .claude/skills from workDir
+// up to the git root and, with nested, those anywhere below the root. It
+// also returns the project file, the closest .claude/skillex.yaml that
+// exists on the way up, or workDir's.
+func projectSkills(workDir, home string, nested bool) (dirs []discovery.LocalSkillsDir, projectFile string) {
+	root := gitRoot(workDir)
+	if root == home {
+		// A dotfiles repository in home does not make every folder a project.
+		root = ""
+	}
+	candidates := projectDirs(root, workDir)
+	if nested && root != "" {
+		candidates = append(candidates, nestedDirs(root)...)
+	}
+
+	seen := make(map[string]bool)
+	for _, dir := range candidates {
+		if dir == home || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if path := filepath.Join(dir, ".claude", "skills"); isDir(path) {
+			dirs = append(dirs, discovery.LocalSkillsDir{Path: path, Name: projectLabel(root, dir)})
+		}
+	}
+
+	up := projectDirs(root, workDir)
+	projectFile = filepath.Join(workDir, filepath.FromSlash(profile.ProjectFileName))
+	for i := len(up) - 1; i >= 0; i-- {
+		if up[i] == home {
+			continue
+		}
+		path := filepath.Join(up[i], filepath.FromSlash(profile.ProjectFileName))
+		if _, err := os.Stat(path); err == nil {
+			projectFile = path
+			break
+		}
+	}
+	return dirs, projectFile
+}
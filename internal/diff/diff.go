@@ -0,0 +1,237 @@
+// Package diff compares two skill documents line by line, for the unified
+// output of `skillex diff` and the side-by-side view in the TUI.
+package diff
+
+import "strings"
+
+// Kind says which side of a comparison a line belongs to.
+type Kind int
+
+const (
+	// Equal lines exist in both documents.
+	Equal Kind = iota
+	// Delete lines exist only in the old document.
+	Delete
+	// Insert lines exist only in the new document.
+	Insert
+)
+
+// Line is one line of a line-by-line comparison.
+type Line struct {
+	Kind Kind
+	Text string
+}
+
+// Document joins a skill's frontmatter and body into the text that is
+// compared, laid out like the SKILL.md it came from.
+func Document(frontmatter, body string) string {
+	if frontmatter == "" {
+		return body
+	}
+	return "---\n" + frontmatter + "\n---\n\n" + body
+}
+
+// Lines compares a and b and returns every line of both, in order, marked
+// as equal, deleted from a or inserted in b. It uses a longest common
+// subsequence, so the result is a minimal edit for the line counts of
+// skill files.
+func Lines(a, b string) []Line {
+	al, bl := split(a), split(b)
+
+	// Common prefix and suffix need no table.
+	pre := 0
+	for pre < len(al) && pre < len(bl) && al[pre] == bl[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(al)-pre && suf < len(bl)-pre && al[len(al)-1-suf] == bl[len(bl)-1-suf] {
+		suf++
+	}
+
+	var out []Line
+	for _, l := range al[:pre] {
+		out = append(out, Line{Equal, l})
+	}
+	out = append(out, lcs(al[pre:len(al)-suf], bl[pre:len(bl)-suf])...)
+	for _, l := range al[len(al)-suf:] {
+		out = append(out, Line{Equal, l})
+	}
+	return out
+}
+
+// Changed reports whether lines contain any insertion or deletion.
+func Changed(lines []Line) bool {
+	for _, l := range lines {
+		if l.Kind != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// lcs diffs a and b with the classic dynamic-programming table, where
+// n[i][j] is the length of the longest common subsequence of a[i:], b[j:].
+func lcs(a, b []string) []Line {
+	n := make([][]int, len(a)+1)
+	for i := range n {
+		n[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				n[i][j] = n[i+1][j+1] + 1
+			} else {
+				n[i][j] = max(n[i+1][j], n[i][j+1])
+			}
+		}
+	}
+
+	var out []Line
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, Line{Equal, a[i]})
+			i++
+			j++
+		case n[i+1][j] >= n[i][j+1]:
+			out = append(out, Line{Delete, a[i]})
+			i++
+		default:
+			out = append(out, Line{Insert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, Line{Delete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		out = append(out, Line{Insert, b[j]})
+	}
+	return out
+}
+
+// split breaks text into lines. A trailing newline does not add an empty
+// last line, and empty text has no lines.
+func split(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Row is one row of a side-by-side view. Left or Right is absent (number
+// 0) when the line exists on one side only.
+type Row struct {
+	Left, Right     string
+	LeftNo, RightNo int
+}
+
+// Changed reports whether the two sides of the row differ.
+func (r Row) Changed() bool {
+	return r.LeftNo == 0 || r.RightNo == 0 || r.Left != r.Right
+}
+
+// SideBySide pairs the lines for a two-column view. A run of deletions
+// followed by insertions is shown as changed rows, old next to new.
+func SideBySide(lines []Line) []Row {
+	var rows []Row
+	leftNo, rightNo := 0, 0
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == Equal {
+			leftNo++
+			rightNo++
+			rows = append(rows, Row{Left: lines[i].Text, Right: lines[i].Text, LeftNo: leftNo, RightNo: rightNo})
+			i++
+			continue
+		}
+
+		var dels, ins []string
+		for ; i < len(lines) && lines[i].Kind == Delete; i++ {
+			dels = append(dels, lines[i].Text)
+		}
+		for ; i < len(lines) && lines[i].Kind == Insert; i++ {
+			ins = append(ins, lines[i].Text)
+		}
+		for k := 0; k < max(len(dels), len(ins)); k++ {
+			var r Row
+			if k < len(dels) {
+				leftNo++
+				r.Left, r.LeftNo = dels[k], leftNo
+			}
+			if k < len(ins) {
+				rightNo++
+				r.Right, r.RightNo = ins[k], rightNo
+			}
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// Hunk is a run of lines around a change, as printed in unified output.
+type Hunk struct {
+	// OldStart and NewStart are the 1-based line numbers of the hunk's
+	// first line in each document.
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// Hunks groups the changes in lines with up to context unchanged lines
+// around each, merging changes that are closer than that.
+func Hunks(lines []Line, context int) []Hunk {
+	var hunks []Hunk
+	oldNo, newNo := 1, 1
+	// olds and news hold each line's number in the old and new document.
+	olds := make([]int, len(lines))
+	news := make([]int, len(lines))
+	for i, l := range lines {
+		olds[i], news[i] = oldNo, newNo
+		if l.Kind != Insert {
+			oldNo++
+		}
+		if l.Kind != Delete {
+			newNo++
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == Equal {
+			i++
+			continue
+		}
+		start := max(i-context, 0)
+		end := i
+		// Extend while the next change is within 2*context equal lines.
+		for end < len(lines) {
+			if lines[end].Kind != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].Kind == Equal {
+				run++
+			}
+			if run < len(lines) && run-end <= 2*context {
+				end = run
+				continue
+			}
+			end = min(end+context, len(lines))
+			break
+		}
+
+		h := Hunk{OldStart: olds[start], NewStart: news[start], Lines: lines[start:end]}
+		for _, l := range h.Lines {
+			if l.Kind != Insert {
+				h.OldLines++
+			}
+			if l.Kind != Delete {
+				h.NewLines++
+			}
+		}
+		hunks = append(hunks, h)
+		i = end
+	}
+	return hunks
+}
@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func render(lines []Line) string {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString([]string{" ", "-", "+"}[l.Kind] + l.Text + "\n")
+	}
+	return b.String()
+}
+
+func TestLines(t *testing.T) {
+	got := render(Lines("a\nb\nc\nd\n", "a\nB\nc\nd\ne"))
+	want := " a\n-b\n+B\n c\n d\n+e\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if Changed(Lines("same\n", "same")) {
+		t.Error("expected a trailing newline not to count as a change")
+	}
+	if got := render(Lines("", "x")); got != "+x\n" {
+		t.Errorf("expected a pure insertion, got %q", got)
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	rows := SideBySide(Lines("a\nold1\nold2\nz", "a\nnew1\nz"))
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %+v", rows)
+	}
+	if rows[1].Left != "old1" || rows[1].Right != "new1" || !rows[1].Changed() {
+		t.Errorf("expected old1 next to new1, got %+v", rows[1])
+	}
+	if rows[2].Left != "old2" || rows[2].RightNo != 0 {
+		t.Errorf("expected old2 alone on the left, got %+v", rows[2])
+	}
+	if rows[3].LeftNo != 4 || rows[3].RightNo != 3 || rows[3].Changed() {
+		t.Errorf("expected z as line 4 and 3, got %+v", rows[3])
+	}
+}
+
+func TestHunks(t *testing.T) {
+	var a, b []string
+	for i := 1; i <= 20; i++ {
+		a = append(a, fmt.Sprint(i))
+		b = append(b, fmt.Sprint(i))
+	}
+	b[1] = "two"
+	b[4] = "five"
+	b[17] = "eighteen"
+	hunks := Hunks(Lines(strings.Join(a, "\n"), strings.Join(b, "\n")), 3)
+	if len(hunks) != 2 {
+		t.Fatalf("expected nearby changes merged into 2 hunks, got %d", len(hunks))
+	}
+	if h := hunks[0]; h.OldStart != 1 || h.OldLines != 8 || h.NewLines != 8 {
+		t.Errorf("unexpected first hunk %+v", h)
+	}
+	if h := hunks[1]; h.OldStart != 15 || h.NewStart != 15 || h.OldLines != 6 {
+		t.Errorf("unexpected second hunk %+v", h)
+	}
+}
@@ -0,0 +1,167 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MarketplaceManifestPath is where a marketplace lists its plugins,
+// relative to the marketplace root.
+const MarketplaceManifestPath = ".claude-plugin/marketplace.json"
+
+type marketplaceManifest struct {
+	Metadata struct {
+		// PluginRoot is prepended to relative plugin sources.
+		PluginRoot string `json:"pluginRoot"`
+	} `json:"metadata"`
+	Plugins []struct {
+		Name string `json:"name"`
+		// Source is a path relative to the marketplace root, or an object
+		// naming a remote repository.
+		Source  json.RawMessage `json:"source"`
+		Version string          `json:"version"`
+	} `json:"plugins"`
+}
+
+// MarketplacePlugin is a plugin a local marketplace clone offers from its
+// own repository.
+type MarketplacePlugin struct {
+	Name string
+	// Version is the version the marketplace lists; the plugin's manifest
+	// may name another.
+	Version     string
+	Dir         string
+	Marketplace Marketplace
+}
+
+// marketplacePlugins lists the plugins of the local clone of m whose source
+// lies inside the clone, sorted by name. A marketplace that is not cloned
+// yields none.
+func marketplacePlugins(pluginsDir string, m Marketplace) ([]MarketplacePlugin, error) {
+	root := m.InstallLocation
+	if root == "" {
+		root = filepath.Join(pluginsDir, "marketplaces", m.Name)
+	}
+	data, err := os.ReadFile(filepath.Join(root, MarketplaceManifestPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest marketplaceManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s of %s: %w", filepath.Base(MarketplaceManifestPath), m.Name, err)
+	}
+
+	var plugins []MarketplacePlugin
+	for _, p := range manifest.Plugins {
+		dir, ok := localSource(root, manifest.Metadata.PluginRoot, p.Source)
+		if !ok || p.Name == "" {
+			continue
+		}
+		plugins = append(plugins, MarketplacePlugin{Name: p.Name, Version: p.Version, Dir: dir, Marketplace: m})
+	}
+	sort.SliceStable(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, nil
+}
+
+// FindMarketplacePlugin looks up plugin in the local clone of the
+// marketplace named market.
+func FindMarketplacePlugin(pluginsFile, plugin, market string) (MarketplacePlugin, error) {
+	pluginsDir := filepath.Dir(pluginsFile)
+	markets, err := LoadMarketplaces(filepath.Join(pluginsDir, MarketplacesFileName))
+	if err != nil {
+		return MarketplacePlugin{}, err
+	}
+	m, ok := markets[market]
+	if !ok {
+		return MarketplacePlugin{}, fmt.Errorf("unknown marketplace %q", market)
+	}
+	plugins, err := marketplacePlugins(pluginsDir, m)
+	if err != nil {
+		return MarketplacePlugin{}, err
+	}
+	for _, p := range plugins {
+		if p.Name == plugin {
+			return p, nil
+		}
+	}
+	return MarketplacePlugin{}, fmt.Errorf("marketplace %s has no local plugin %q", market, plugin)
+}
+
+// DiscoverAvailable finds the skills of plugins offered by the marketplace
+// clones Claude Code keeps on disk but not installed. Only plugins whose
+// source lies inside the clone can be read without network access; plugins
+// fetched from elsewhere are skipped. The skills have Available set. A
+// nil cache reads every skill.
+func DiscoverAvailable(pluginsFile string, cache *Cache) ([]Skill, []Failure, error) {
+	installed, err := ReadInstalled(pluginsFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	pluginsDir := filepath.Dir(pluginsFile)
+	markets, err := LoadMarketplaces(filepath.Join(pluginsDir, MarketplacesFileName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(markets))
+	for name := range markets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sources []source
+	var failures []Failure
+	for _, name := range names {
+		market := markets[name]
+		plugins, err := marketplacePlugins(pluginsDir, market)
+		if err != nil {
+			failures = append(failures, Failure{Plugin: "@" + name, Dir: market.InstallLocation, Err: err})
+			continue
+		}
+		for _, p := range plugins {
+			if _, ok := installed.Plugins[p.Name+"@"+name]; ok {
+				continue
+			}
+			src := pluginSource(p.Dir, p.Name)
+			finishPlugin := src.finish
+			src.finish = func(found []Skill) {
+				finishPlugin(found)
+				for i := range found {
+					if found[i].PluginVersion == "" {
+						found[i].PluginVersion = p.Version
+					}
+					found[i].Marketplace = name
+					found[i].MarketplaceInfo = &market
+					found[i].Available = true
+				}
+			}
+			sources = append(sources, src)
+		}
+	}
+	skills, failed := readSources(sources, cache)
+	return skills, append(failures, failed...), nil
+}
+
+// localSource resolves a marketplace plugin source to a directory inside
+// root. ok is false for remote sources and paths that leave root.
+func localSource(root, pluginRoot string, source json.RawMessage) (dir string, ok bool) {
+	var rel string
+	if json.Unmarshal(source, &rel) != nil || rel == "" {
+		return "", false
+	}
+	if !strings.HasPrefix(rel, "./") && !strings.HasPrefix(rel, "../") && pluginRoot != "" {
+		rel = pluginRoot + "/" + rel
+	}
+	rel = filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.Join(root, rel), true
+}
@@ -0,0 +1,184 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// CacheFileName is the discovery cache's name inside the skillex directory.
+const CacheFileName = "discovery-cache.gob"
+
+// cacheVersion is bumped whenever parsing changes what a cached entry would
+// hold, so entries written by an older skillex are parsed again.
+const cacheVersion = 3
+
+// Cache remembers what skill files parsed into and the types of bundled
+// files of previous runs, keyed by path and checked against their
+// modification time and size, so unchanged skills are neither read nor
+// parsed and unchanged files not sniffed again. Folders are still listed on
+// every run. The cache is stored with encoding/gob, which decodes thousands
+// of cached skill bodies several times faster than JSON. A nil *Cache caches
+// nothing. It is safe for concurrent use.
+type Cache struct {
+	path string
+
+	mu     sync.Mutex
+	skills map[string]skillEntry
+	files  map[string]fileEntry
+	used   map[string]bool
+	dirty  bool
+}
+
+// skillEntry is everything a skill file parsed into. Name is the name from
+// the frontmatter, empty when it has none.
+type skillEntry struct {
+	ModTime      time.Time
+	Size         int64
+	Name         string
+	Description  string
+	AllowedTools []string
+	Frontmatter  string
+	Content      string
+}
+
+// fileEntry is what a bundled file was classified as.
+type fileEntry struct {
+	ModTime time.Time
+	Size    int64
+	Type    FileType
+}
+
+type cacheFile struct {
+	Version int
+	Skills  map[string]skillEntry
+	Files   map[string]fileEntry
+}
+
+// OpenCache reads the cache at path. A missing, unreadable or outdated cache
+// starts empty: it only saves time, so it is rebuilt rather than reported.
+// An empty path yields nil, which caches nothing.
+func OpenCache(path string) *Cache {
+	if path == "" {
+		return nil
+	}
+	c := &Cache{path: path, skills: make(map[string]skillEntry), files: make(map[string]fileEntry), used: make(map[string]bool)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	var f cacheFile
+	if gob.NewDecoder(bytes.NewReader(data)).Decode(&f) != nil || f.Version != cacheVersion {
+		return c
+	}
+	// gob leaves out empty maps.
+	if f.Skills != nil {
+		c.skills = f.Skills
+	}
+	if f.Files != nil {
+		c.files = f.Files
+	}
+	return c
+}
+
+// skill returns what the skill file parsed into if it was cached with the
+// same modification time and size.
+func (c *Cache) skill(file string, info os.FileInfo) (skillEntry, bool) {
+	if c == nil {
+		return skillEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.skills[file]
+	if !ok || !e.ModTime.Equal(info.ModTime()) || e.Size != info.Size() {
+		return skillEntry{}, false
+	}
+	c.used[file] = true
+	return e, true
+}
+
+// storeSkill records what the skill file parsed into.
+func (c *Cache) storeSkill(file string, e skillEntry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skills[file] = e
+	c.used[file] = true
+	c.dirty = true
+}
+
+// fileType returns the type of the bundled file at path, classifying it
+// only when it changed since it was cached.
+func (c *Cache) fileType(path string, info os.FileInfo) FileType {
+	if c == nil {
+		return classifyFile(path)
+	}
+	c.mu.Lock()
+	e, ok := c.files[path]
+	if ok && e.ModTime.Equal(info.ModTime()) && e.Size == info.Size() {
+		c.used[path] = true
+		c.mu.Unlock()
+		return e.Type
+	}
+	c.mu.Unlock()
+
+	e = fileEntry{ModTime: info.ModTime(), Size: info.Size(), Type: classifyFile(path)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[path] = e
+	c.used[path] = true
+	c.dirty = true
+	return e.Type
+}
+
+// Save writes the cache if anything changed. Entries for files that were
+// not looked up since OpenCache and no longer exist are dropped.
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for file := range c.skills {
+		if c.gone(file) {
+			delete(c.skills, file)
+		}
+	}
+	for file := range c.files {
+		if c.gone(file) {
+			delete(c.files, file)
+		}
+	}
+	if !c.dirty {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cacheFile{Version: cacheVersion, Skills: c.skills, Files: c.files}); err != nil {
+		return fmt.Errorf("encoding discovery cache: %w", err)
+	}
+	if err := state.WriteFileAtomic(c.path, buf.Bytes()); err != nil {
+		return fmt.Errorf("writing discovery cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// gone reports whether file was not looked up since OpenCache and no longer
+// exists, marking the cache changed if so. c.mu must be held.
+func (c *Cache) gone(file string) bool {
+	if c.used[file] {
+		return false
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		return false
+	}
+	c.dirty = true
+	return true
+}
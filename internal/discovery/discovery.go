@@ -2,11 +2,16 @@ package discovery
 
 import (
 	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -27,6 +32,17 @@ const (
 	ActivationPassive
 )
 
+// String returns the plain word for the style: directive, passive or unknown.
+func (a ActivationStyle) String() string {
+	switch a {
+	case ActivationDirective:
+		return "directive"
+	case ActivationPassive:
+		return "passive"
+	}
+	return "unknown"
+}
+
 // AssessActivationStyle returns the invocation style based on description wording.
 // Directive descriptions activate reliably; passive descriptions are often ignored.
 func AssessActivationStyle(description string) ActivationStyle {
@@ -54,102 +70,393 @@ type Skill struct {
 	Frontmatter     string
 	ActivationStyle ActivationStyle
 	Enabled         bool
+	// AllowedTools lists the tools granted by the allowed-tools frontmatter
+	// key. Claude may use them without asking while the skill is active.
+	AllowedTools []string
+	// Files lists the supporting files bundled next to SKILL.md, such as
+	// scripts, references and templates. SKILL.md itself is not included,
+	// nor are hidden and dependency folders such as node_modules.
+	Files []SkillFile
+	// FilesTruncated is set when the skill bundles more than MaxFiles
+	// files; Files then holds only the first MaxFiles found.
+	FilesTruncated bool
+	// ModTime and Size describe FilePath when the skill was loaded. Toggling
+	// refuses to touch a file that changed since.
+	ModTime time.Time
+	Size    int64
+	// Conflict is set when both SKILL.md and SKILL.md.disabled exist. The
+	// skill is then loaded from SKILL.md.
+	Conflict bool
+	// Reverted is set when the skill is recorded as disabled by the state
+	// backend but its SKILL.md is back, typically after a plugin update.
+	Reverted bool
+	// PluginVersion and GitCommitSha identify the installed plugin release
+	// the skill came from. Both are empty for local skills.
+	PluginVersion string
+	GitCommitSha  string
+	// PluginDir is the root of the plugin the skill came from, and Manifest
+	// its parsed .claude-plugin/plugin.json, shared by all of the plugin's
+	// skills. PluginDir is empty for local skills; Manifest is nil when the
+	// plugin has none.
+	PluginDir string
+	Manifest  *Manifest
+	// Marketplace is the marketplace the plugin was installed from, the part
+	// of its key after "@", and MarketplaceInfo its entry in Claude Code's
+	// marketplace registry. Marketplace is empty for local skills;
+	// MarketplaceInfo is nil when the registry does not list it.
+	Marketplace     string
+	MarketplaceInfo *Marketplace
+	// Available marks a skill of a plugin that is not installed, found in a
+	// local marketplace clone by DiscoverAvailable.
+	Available bool
+	// Category is the slash-separated path of the folders between the
+	// skills folder and the skill's own, empty for a skill directly inside
+	// it. Claude Code only loads skills without a category.
+	Category string
+}
+
+// ID returns the plugin-qualified skill name, "plugin:skill", the same form
+// Claude Code uses to invoke plugin skills.
+func (s Skill) ID() string {
+	return s.Plugin + ":" + s.Name
+}
+
+// Loaded reports whether Claude Code loads the skill: it is enabled and not
+// nested in a category folder.
+func (s Skill) Loaded() bool {
+	return s.Enabled && s.Category == ""
+}
+
+// Dir returns the skill's directory, the one containing SKILL.md.
+func (s Skill) Dir() string {
+	return filepath.Dir(s.FilePath)
 }
 
-type installedPlugins struct {
-	Version int                         `json:"version"`
-	Plugins map[string][]pluginInstance `json:"plugins"`
+// Record is the JSON form of a skill used by exports and listings.
+type Record struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Plugin       string   `json:"plugin"`
+	Description  string   `json:"description"`
+	Enabled      bool     `json:"enabled"`
+	Path         string   `json:"path"`
+	Activation   string   `json:"activation"`
+	AllowedTools []string `json:"allowedTools,omitempty"`
+	Marketplace  string   `json:"marketplace,omitempty"`
+	Category     string   `json:"category,omitempty"`
 }
 
-type pluginInstance struct {
-	InstallPath string `json:"installPath"`
-	Version     string `json:"version"`
+// Record returns the skill's JSON form.
+func (s Skill) Record() Record {
+	return Record{
+		ID:           s.ID(),
+		Name:         s.Name,
+		Plugin:       s.Plugin,
+		Description:  s.Description,
+		Enabled:      s.Enabled,
+		Path:         s.FilePath,
+		Activation:   s.ActivationStyle.String(),
+		AllowedTools: s.AllowedTools,
+		Marketplace:  s.Marketplace,
+		Category:     s.Category,
+	}
 }
 
 type frontmatter struct {
-	Name        string `yaml:"name"`
-	Description string `yaml:"description"`
+	Name         string   `yaml:"name"`
+	Description  string   `yaml:"description"`
+	AllowedTools toolList `yaml:"allowed-tools"`
+}
+
+// toolList decodes allowed-tools, which Claude Code accepts either as a
+// comma-separated string ("Read, Bash(git status:*)") or as a YAML list.
+type toolList []string
+
+func (t *toolList) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.SequenceNode:
+		var items []string
+		if err := value.Decode(&items); err != nil {
+			return err
+		}
+		for _, item := range items {
+			*t = append(*t, splitTools(item)...)
+		}
+	case yaml.ScalarNode:
+		*t = splitTools(value.Value)
+	}
+	return nil
+}
+
+// splitTools splits a comma-separated tool list, ignoring commas inside
+// parentheses such as "Bash(git diff:*, git log:*)".
+func splitTools(s string) []string {
+	var tools []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				if tool := strings.TrimSpace(s[start:i]); tool != "" {
+					tools = append(tools, tool)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if tool := strings.TrimSpace(s[start:]); tool != "" {
+		tools = append(tools, tool)
+	}
+	return tools
 }
 
+// MaxDepth bounds how many folders deep discoverSkillsInDir looks for
+// skills below a skills folder. Skills directly inside it are at depth 1.
+const MaxDepth = 4
+
+// readWorkers bounds how many skills are read at a time, across all plugins
+// and local folders being discovered.
+var readWorkers = 2 * runtime.GOMAXPROCS(0)
+
 // discoverSkillsInDir walks subdirectories of dir, reads SKILL.md (or
 // SKILL.md.disabled) files and returns discovered skills. A skill whose
 // file is named SKILL.md.disabled has Enabled=false and is invisible to
 // Claude Code. Returns nil if dir doesn't exist.
-func discoverSkillsInDir(dir string, pluginName string) []Skill {
-	entries, err := os.ReadDir(dir)
+func discoverSkillsInDir(dir string, pluginName string, cache *Cache) ([]Skill, []Failure) {
+	return readSources([]source{{plugin: pluginName, dirs: findSkillDirs(dir)}}, cache)
+}
+
+// findSkillDirs returns the skill folders below dir in walk order. Folders
+// without a skill are searched in turn, up to MaxDepth, and name the
+// Category of the skills inside them. Symlinked folders are followed; each
+// real folder is visited once, so links back up the tree do not loop.
+func findSkillDirs(dir string) []skillDir {
+	real, err := filepath.EvalSymlinks(dir)
 	if err != nil {
 		return nil
 	}
+	w := skillWalker{visited: map[string]bool{real: true}}
+	w.walk(dir, real, "", 1)
+	return w.found
+}
 
-	var skills []Skill
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
+// skillWalker collects the skill folders below one skills folder.
+type skillWalker struct {
+	visited map[string]bool
+	found   []skillDir
+}
 
-		enabledPath := filepath.Join(dir, entry.Name(), "SKILL.md")
-		disabledPath := enabledPath + ".disabled"
+// skillDir is a folder holding a skill file, and the skill's category.
+type skillDir struct {
+	path, category string
+}
 
-		// Prefer SKILL.md when both exist.
-		skillFile := enabledPath
-		enabled := true
-		if _, err := os.Stat(enabledPath); os.IsNotExist(err) {
-			if _, err := os.Stat(disabledPath); err != nil {
+// walk collects the skill folders among the subdirectories of dir, whose
+// path with symlinks resolved is real. dir is depth-1 folders below the
+// skills folder and has the given category.
+func (w *skillWalker) walk(dir, real, category string, depth int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		sub := filepath.Join(dir, entry.Name())
+		subReal := filepath.Join(real, entry.Name())
+		if !entry.IsDir() {
+			if entry.Type()&os.ModeSymlink == 0 {
+				continue
+			}
+			if info, err := os.Stat(sub); err != nil || !info.IsDir() {
+				continue
+			}
+			if subReal, err = filepath.EvalSymlinks(sub); err != nil {
 				continue
 			}
-			skillFile = disabledPath
-			enabled = false
 		}
-
-		content, err := os.ReadFile(skillFile)
-		if err != nil {
+		if w.visited[subReal] {
 			continue
 		}
+		w.visited[subReal] = true
 
-		fm, rawFM, body, err := parseFrontmatter(content)
-		if err != nil {
-			continue
+		switch {
+		case hasSkillFile(sub):
+			w.found = append(w.found, skillDir{path: sub, category: category})
+		case depth < MaxDepth && !strings.HasPrefix(entry.Name(), "."):
+			w.walk(sub, subReal, path.Join(category, entry.Name()), depth+1)
+		}
+	}
+}
+
+// hasSkillFile reports whether dir holds SKILL.md or SKILL.md.disabled,
+// even one that cannot be read.
+func hasSkillFile(dir string) bool {
+	enabledPath, disabledPath := skillPaths(dir)
+	for _, p := range []string{enabledPath, disabledPath} {
+		if _, err := os.Lstat(p); !os.IsNotExist(err) {
+			return true
 		}
+	}
+	return false
+}
+
+// source is the skill folders of one plugin or local folder, waiting to be
+// read.
+type source struct {
+	plugin string
+	dirs   []skillDir
+	// failures were found while collecting dirs.
+	failures []Failure
+	// finish, if set, completes the source's skills once they are read.
+	finish func([]Skill)
+}
 
-		name := fm.Name
-		if name == "" {
-			name = entry.Name()
+// readSources reads the skills of all sources with up to readWorkers
+// goroutines, consulting cache, and returns them in the order of sources
+// and their dirs.
+func readSources(sources []source, cache *Cache) ([]Skill, []Failure) {
+	type job struct {
+		dir    skillDir
+		plugin string
+	}
+	var jobs []job
+	for _, src := range sources {
+		for _, d := range src.dirs {
+			jobs = append(jobs, job{dir: d, plugin: src.plugin})
 		}
+	}
 
-		skills = append(skills, Skill{
-			Name:            name,
-			Description:     fm.Description,
-			Plugin:          pluginName,
-			FilePath:        skillFile,
-			Content:         body,
-			Frontmatter:     rawFM,
-			ActivationStyle: AssessActivationStyle(fm.Description),
-			Enabled:         enabled,
+	type result struct {
+		skill Skill
+		err   error
+	}
+	results := make([]result, len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(readWorkers, len(jobs)) {
+		wg.Go(func() {
+			for i := range next {
+				results[i].skill, results[i].err = readSkill(jobs[i].dir.path, jobs[i].plugin, cache)
+			}
 		})
 	}
-	return skills
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var skills []Skill
+	var failures []Failure
+	i := 0
+	for _, src := range sources {
+		var found []Skill
+		failures = append(failures, src.failures...)
+		for _, d := range src.dirs {
+			switch r := results[i]; {
+			case r.err == nil:
+				r.skill.Category = d.category
+				found = append(found, r.skill)
+			case !errors.Is(r.err, errNoSkill):
+				failures = append(failures, Failure{Plugin: src.plugin, Dir: d.path, Err: r.err})
+			}
+			i++
+		}
+		if src.finish != nil {
+			src.finish(found)
+		}
+		skills = append(skills, found...)
+	}
+	return skills, failures
+}
+
+// errNoSkill means a directory holds neither SKILL.md nor SKILL.md.disabled.
+var errNoSkill = errors.New("no SKILL.md")
+
+// loadSkill reads the skill in skillDir. ok is false when the directory holds
+// no readable SKILL.md or SKILL.md.disabled.
+func loadSkill(skillDir, pluginName string) (skill Skill, ok bool) {
+	skill, err := readSkill(skillDir, pluginName, nil)
+	return skill, err == nil
 }
 
-// ToggleSkill renames a skill's file between SKILL.md and SKILL.md.disabled,
-// toggling its visibility to Claude Code. It updates FilePath and Enabled
-// in place.
-func ToggleSkill(skill *Skill) error {
-	if skill.Enabled {
-		newPath := skill.FilePath + ".disabled"
-		if err := os.Rename(skill.FilePath, newPath); err != nil {
-			return fmt.Errorf("disabling skill: %w", err)
+// readSkill reads the skill in skillDir, returning errNoSkill when there is
+// none and another error when its file cannot be read or parsed. The parsed
+// skill file and the types of bundled files are taken from cache when they
+// have not changed since.
+func readSkill(skillDir, pluginName string, cache *Cache) (Skill, error) {
+	enabledPath, disabledPath := skillPaths(skillDir)
+
+	// Prefer SKILL.md when both exist, but flag the conflict.
+	skillFile := enabledPath
+	enabled := true
+	info, err := os.Stat(enabledPath)
+	if os.IsNotExist(err) {
+		if info, err = os.Stat(disabledPath); os.IsNotExist(err) {
+			return Skill{}, errNoSkill
+		} else if err != nil {
+			return Skill{}, err
 		}
-		skill.FilePath = newPath
-		skill.Enabled = false
-	} else {
-		newPath := strings.TrimSuffix(skill.FilePath, ".disabled")
-		if err := os.Rename(skill.FilePath, newPath); err != nil {
-			return fmt.Errorf("enabling skill: %w", err)
+		skillFile = disabledPath
+		enabled = false
+	} else if err != nil {
+		return Skill{}, err
+	}
+
+	parsed, ok := cache.skill(skillFile, info)
+	if !ok {
+		if parsed, err = parseSkillFile(skillFile); err != nil {
+			return Skill{}, err
 		}
-		skill.FilePath = newPath
-		skill.Enabled = true
+		parsed.ModTime, parsed.Size = info.ModTime(), info.Size()
+		cache.storeSkill(skillFile, parsed)
 	}
-	return nil
+
+	name := parsed.Name
+	if name == "" {
+		name = filepath.Base(skillDir)
+	}
+
+	files, truncated := inventoryFiles(skillDir, cache)
+	return Skill{
+		Name:            name,
+		Description:     parsed.Description,
+		Plugin:          pluginName,
+		FilePath:        skillFile,
+		Content:         parsed.Content,
+		Frontmatter:     parsed.Frontmatter,
+		ActivationStyle: AssessActivationStyle(parsed.Description),
+		Enabled:         enabled,
+		AllowedTools:    parsed.AllowedTools,
+		Files:           files,
+		FilesTruncated:  truncated,
+		ModTime:         info.ModTime(),
+		Size:            info.Size(),
+		Conflict:        enabled && fileExists(disabledPath),
+	}, nil
+}
+
+// parseSkillFile reads and parses the skill file at path.
+func parseSkillFile(path string) (skillEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return skillEntry{}, err
+	}
+	yamlBlock, body := splitFrontmatter(content)
+	var fm frontmatter
+	if err := yaml.Unmarshal(yamlBlock, &fm); err != nil {
+		return skillEntry{}, fmt.Errorf("parsing frontmatter of %s: %w", filepath.Base(path), err)
+	}
+	return skillEntry{
+		Name:         fm.Name,
+		Description:  fm.Description,
+		AllowedTools: fm.AllowedTools,
+		Frontmatter:  string(bytes.TrimSpace(yamlBlock)),
+		Content:      body,
+	}, nil
 }
 
 // LocalSkillsDir pairs a .claude/skills path with a display name.
@@ -158,59 +465,176 @@ type LocalSkillsDir struct {
 	Name string
 }
 
+// Failure is a skill directory whose SKILL.md could not be read or parsed.
+// Claude Code cannot load such a skill either.
+type Failure struct {
+	Plugin string
+	Dir    string
+	Err    error
+}
+
 // Discover reads installed_plugins.json and finds all skills.
 // Skills from localDirs are also included, each labeled with its Name.
+// Skills that fail to parse are skipped; DiscoverAll reports them.
 func Discover(pluginsFile string, localDirs []LocalSkillsDir) ([]Skill, error) {
-	data, err := os.ReadFile(pluginsFile)
+	skills, _, err := DiscoverAll(pluginsFile, localDirs)
+	return skills, err
+}
+
+// DiscoverAll is Discover that also returns the skills it had to skip.
+func DiscoverAll(pluginsFile string, localDirs []LocalSkillsDir) ([]Skill, []Failure, error) {
+	return DiscoverCached(pluginsFile, localDirs, nil)
+}
+
+// DiscoverCached is DiscoverAll that takes unchanged skill files and the
+// types of unchanged bundled files from cache and records the others in it.
+// The caller saves the cache.
+func DiscoverCached(pluginsFile string, localDirs []LocalSkillsDir, cache *Cache) ([]Skill, []Failure, error) {
+	installed, err := ReadInstalled(pluginsFile)
 	if err != nil {
-		return nil, fmt.Errorf("reading plugins file: %w", err)
+		return nil, nil, err
 	}
 
-	var installed installedPlugins
-	if err := json.Unmarshal(data, &installed); err != nil {
-		return nil, fmt.Errorf("parsing plugins file: %w", err)
+	// Walk plugins in key order so the skill order is stable across runs.
+	keys := make([]string, 0, len(installed.Plugins))
+	for key := range installed.Plugins {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	var skills []Skill
-	for key, instances := range installed.Plugins {
+	// The registry only adds the marketplace source; plugins are found
+	// without it, so a broken registry is ignored.
+	markets, _ := LoadMarketplaces(filepath.Join(filepath.Dir(pluginsFile), MarketplacesFileName))
+
+	var sources []source
+	for _, key := range keys {
+		instances := installed.Plugins[key]
 		if len(instances) == 0 {
 			continue
 		}
 		inst := instances[0]
 
-		pluginName := key
-		if idx := strings.Index(key, "@"); idx != -1 {
-			pluginName = key[:idx]
+		pluginName, marketName, _ := strings.Cut(key, "@")
+		var market *Marketplace
+		if m, ok := markets[marketName]; ok {
+			market = &m
 		}
 
-		skills = append(skills, discoverSkillsInDir(filepath.Join(inst.InstallPath, "skills"), pluginName)...)
+		src := pluginSource(inst.InstallPath, pluginName)
+		finishPlugin := src.finish
+		src.finish = func(found []Skill) {
+			finishPlugin(found)
+			for i := range found {
+				if inst.Version != "" {
+					found[i].PluginVersion = inst.Version
+				}
+				found[i].GitCommitSha = inst.GitCommitSha
+				found[i].Marketplace = marketName
+				found[i].MarketplaceInfo = market
+			}
+		}
+		sources = append(sources, src)
 	}
 
 	for _, d := range localDirs {
-		skills = append(skills, discoverSkillsInDir(d.Path, d.Name)...)
+		sources = append(sources, source{plugin: d.Name, dirs: findSkillDirs(d.Path)})
 	}
 
-	return skills, nil
+	skills, failures := readSources(sources, cache)
+	return skills, failures, nil
 }
 
-func parseFrontmatter(content []byte) (fm frontmatter, rawYAML string, body string, err error) {
-	trimmed := bytes.TrimSpace(content)
-	if !bytes.HasPrefix(trimmed, []byte("---")) {
-		return fm, "", string(content), nil
+// DiscoverPlugin treats dir, typically a plugin repository that is not
+// installed yet, as a skill source. The plugin is named after its manifest,
+// or after dir when there is none, and its skills are read from dir/skills
+// and the manifest's custom skills paths, or from dir itself when it has no
+// skills folder.
+func DiscoverPlugin(dir string) ([]Skill, []Failure, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	name := filepath.Base(abs)
+	if manifest != nil && manifest.Name != "" {
+		name = manifest.Name
 	}
 
-	rest := trimmed[3:]
-	idx := bytes.Index(rest, []byte("\n---"))
-	if idx == -1 {
-		return fm, "", string(content), nil
+	if _, err := os.Stat(filepath.Join(dir, "skills")); err != nil && (manifest == nil || len(manifest.Skills) == 0) {
+		skills, failures := discoverSkillsInDir(dir, name, nil)
+		return skills, failures, nil
 	}
+	skills, failures := discoverPluginSkills(dir, name, nil)
+	return skills, failures, nil
+}
+
+// discoverPluginSkills reads the skills of the plugin rooted at dir from
+// skills/ and the manifest's custom skills paths. A custom path may name a
+// folder of skills or a single skill folder. An unreadable manifest is
+// reported as a failure and the default folder is still read.
+func discoverPluginSkills(dir, pluginName string, cache *Cache) ([]Skill, []Failure) {
+	return readSources([]source{pluginSource(dir, pluginName)}, cache)
+}
 
-	yamlBlock := rest[:idx]
-	bodyBytes := rest[idx+4:]
+// pluginSource collects the skill folders of the plugin rooted at dir for
+// discoverPluginSkills.
+func pluginSource(dir, pluginName string) source {
+	src := source{plugin: pluginName}
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		src.failures = append(src.failures, Failure{Plugin: pluginName, Dir: filepath.Join(dir, filepath.Dir(ManifestPath)), Err: err})
+	}
 
+	for i, skillsDir := range manifest.skillDirs(dir) {
+		if i > 0 && hasSkillFile(skillsDir) {
+			src.dirs = append(src.dirs, skillDir{path: skillsDir})
+			continue
+		}
+		src.dirs = append(src.dirs, findSkillDirs(skillsDir)...)
+	}
+	src.finish = func(skills []Skill) {
+		for i := range skills {
+			skills[i].PluginDir = dir
+			skills[i].Manifest = manifest
+			if manifest != nil {
+				skills[i].PluginVersion = manifest.Version
+			}
+		}
+	}
+	return src
+}
+
+func parseFrontmatter(content []byte) (fm frontmatter, rawYAML string, body string, err error) {
+	yamlBlock, body := splitFrontmatter(content)
 	if err = yaml.Unmarshal(yamlBlock, &fm); err != nil {
 		return fm, "", string(content), err
 	}
+	return fm, string(bytes.TrimSpace(yamlBlock)), body, nil
+}
 
-	return fm, string(bytes.TrimSpace(yamlBlock)), string(bytes.TrimSpace(bodyBytes)), nil
+// splitFrontmatter separates the YAML block between the leading "---" lines
+// from the body. Without one, the block is nil and the body is all of
+// content.
+func splitFrontmatter(content []byte) (yamlBlock []byte, body string) {
+	trimmed := bytes.TrimSpace(content)
+	if !bytes.HasPrefix(trimmed, []byte("---")) {
+		return nil, string(content)
+	}
+
+	rest := trimmed[3:]
+	idx := bytes.Index(rest, []byte("\n---"))
+	if idx == -1 {
+		return nil, string(content)
+	}
+	return rest[:idx], string(bytes.TrimSpace(rest[idx+4:]))
 }
@@ -1,10 +1,14 @@
 package discovery
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadPlugins(t *testing.T) {
@@ -102,6 +106,45 @@ Some content here.
 	if !strings.Contains(s.Frontmatter, "name: brainstorming") {
 		t.Errorf("expected Frontmatter to contain 'name: brainstorming', got %q", s.Frontmatter)
 	}
+	if s.PluginVersion != "4.2.0" || s.GitCommitSha != "abc123" {
+		t.Errorf("expected plugin release 4.2.0/abc123, got %q/%q", s.PluginVersion, s.GitCommitSha)
+	}
+}
+
+func TestDiscoverOrderIsStable(t *testing.T) {
+	tmpDir := t.TempDir()
+	plugins := map[string][]PluginInstance{}
+	for _, name := range []string{"zeta", "alpha", "mid", "beta", "omega"} {
+		skillDir := filepath.Join(tmpDir, name, "skills", name+"-skill")
+		if err := os.MkdirAll(skillDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("Body.\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		plugins[name+"@market"] = []PluginInstance{{InstallPath: filepath.Join(tmpDir, name)}}
+	}
+	data, err := json.Marshal(InstalledPlugins{Version: 2, Plugins: plugins})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pluginsFile := filepath.Join(tmpDir, "installed_plugins.json")
+	if err := os.WriteFile(pluginsFile, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"alpha", "beta", "mid", "omega", "zeta"}
+	for run := 0; run < 5; run++ {
+		skills, err := Discover(pluginsFile, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, s := range skills {
+			if s.Plugin != want[i] {
+				t.Fatalf("run %d: expected plugins in order %v, got %q at %d", run, want, s.Plugin, i)
+			}
+		}
+	}
 }
 
 func TestDiscoverLocalSkills(t *testing.T) {
@@ -163,6 +206,59 @@ Another content.
 	}
 }
 
+func TestDiscoverNestedSkills(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillsDir := filepath.Join(tmpDir, "skills")
+	shared := filepath.Join(tmpDir, "shared", "lookup")
+
+	write := func(dir, name string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: "+name+"\n---\nBody.\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(skillsDir, "flat"), "flat")
+	write(filepath.Join(skillsDir, "ops", "release", "deploy"), "deploy")
+	write(filepath.Join(skillsDir, "a", "b", "c", "d", "too-deep"), "too-deep")
+	write(shared, "lookup")
+	// A skill's own folders are not searched.
+	write(filepath.Join(skillsDir, "flat", "examples", "inner"), "inner")
+
+	links := map[string]string{
+		filepath.Join(skillsDir, "data", "lookup"): shared,
+		filepath.Join(skillsDir, "ops", "loop"):    skillsDir,
+	}
+	for link, target := range links {
+		if err := os.MkdirAll(filepath.Dir(link), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	skills, failures := discoverSkillsInDir(skillsDir, "local", nil)
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures: %+v", failures)
+	}
+	got := make(map[string]string)
+	for _, s := range skills {
+		got[s.Name] = s.Category
+	}
+	want := map[string]string{"flat": "", "deploy": "ops/release", "lookup": "data"}
+	if len(got) != len(want) {
+		t.Errorf("found %v, want %v", got, want)
+	}
+	for name, category := range want {
+		if c, ok := got[name]; !ok || c != category {
+			t.Errorf("skill %s: category %q (found %v), want %q", name, c, ok, category)
+		}
+	}
+}
+
 func TestAssessActivationStyle(t *testing.T) {
 	tests := []struct {
 		desc     string
@@ -399,6 +495,32 @@ func TestToggleSkillRoundTrip(t *testing.T) {
 	}
 }
 
+func TestRestatFollowsRename(t *testing.T) {
+	skillDir := filepath.Join(t.TempDir(), "my-skill")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	skillFile := filepath.Join(skillDir, "SKILL.md")
+	if err := os.WriteFile(skillFile+".disabled", []byte("Body.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	skill := Skill{Name: "my-skill", FilePath: skillFile, Enabled: true}
+	if err := Restat(&skill); err != nil {
+		t.Fatalf("Restat() error: %v", err)
+	}
+	if skill.Enabled || skill.FilePath != skillFile+".disabled" {
+		t.Errorf("expected disabled skill at %s.disabled, got %+v", skillFile, skill)
+	}
+
+	if err := os.Remove(skillFile + ".disabled"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restat(&skill); err == nil {
+		t.Error("expected an error for a removed skill")
+	}
+}
+
 func TestEnabledSkillHasEnabledTrue(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -436,3 +558,499 @@ Content.
 		t.Error("expected Enabled=true for SKILL.md")
 	}
 }
+
+func TestDiscoverInventoriesSupportingFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pluginsJSON := `{"version": 2, "plugins": {}}`
+	pluginsFile := filepath.Join(tmpDir, "installed_plugins.json")
+	if err := os.WriteFile(pluginsFile, []byte(pluginsJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	localDir := filepath.Join(tmpDir, "skills")
+	skillDir := filepath.Join(localDir, "with-files")
+	if err := os.MkdirAll(filepath.Join(skillDir, "scripts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: with-files\n---\nBody.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "reference.md"), []byte("# Reference\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "scripts", "run.sh"), []byte("#!/bin/sh\necho hi\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "logo.png"), []byte{0x89, 'P', 'N', 'G', 0, 0, 0}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	skills, err := Discover(pluginsFile, []LocalSkillsDir{
+		{Path: localDir, Name: "test"},
+	})
+	if err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	if len(skills) != 1 {
+		t.Fatalf("expected 1 skill, got %d", len(skills))
+	}
+
+	files := skills[0].Files
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files (SKILL.md excluded), got %d: %+v", len(files), files)
+	}
+
+	want := []SkillFile{
+		{Path: "logo.png", Size: 7, Type: FileBinary},
+		{Path: "reference.md", Size: 12, Type: FileText},
+		{Path: "scripts/run.sh", Size: 18, Type: FileScript, Executable: true},
+	}
+	for i, w := range want {
+		if files[i] != w {
+			t.Errorf("file %d: expected %+v, got %+v", i, w, files[i])
+		}
+	}
+	if skills[0].Dir() != skillDir {
+		t.Errorf("expected Dir() %q, got %q", skillDir, skills[0].Dir())
+	}
+}
+
+func TestInventorySkipsDependenciesAndTruncates(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{"run.sh", ".git/config", ".venv/lib/x.py", "node_modules/dep/index.js", "vendor/lib.go", "lib/.cache/blob"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, truncated := inventoryFiles(dir, nil)
+	if len(files) != 1 || files[0].Path != "run.sh" || truncated {
+		t.Errorf("expected only run.sh, got %+v (truncated %v)", files, truncated)
+	}
+
+	for i := range MaxFiles {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("ref-%03d.md", i)), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, truncated = inventoryFiles(dir, nil)
+	if len(files) != MaxFiles || !truncated {
+		t.Errorf("expected %d files and truncated, got %d (truncated %v)", MaxFiles, len(files), truncated)
+	}
+}
+
+func TestSplitTools(t *testing.T) {
+	got := splitTools("Read, Grep,Bash(git diff:*, git log:*) , ")
+	want := []string{"Read", "Grep", "Bash(git diff:*, git log:*)"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tool %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestDiscoverAllReportsParseFailures(t *testing.T) {
+	tmpDir := t.TempDir()
+	pluginsFile := filepath.Join(tmpDir, "installed_plugins.json")
+	if err := os.WriteFile(pluginsFile, []byte(`{"version": 2, "plugins": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	skillsDir := filepath.Join(tmpDir, "skills")
+	for name, content := range map[string]string{
+		"good":   "---\nname: good\n---\nFine.\n",
+		"broken": "---\nname: [unclosed\n---\nBody.\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(skillsDir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(skillsDir, name, "SKILL.md"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A directory without SKILL.md is not a failure.
+	if err := os.MkdirAll(filepath.Join(skillsDir, "notes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	skills, failures, err := DiscoverAll(pluginsFile, []LocalSkillsDir{{Path: skillsDir, Name: "local"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skills) != 1 || skills[0].Name != "good" {
+		t.Errorf("expected only the good skill, got %+v", skills)
+	}
+	if len(failures) != 1 || filepath.Base(failures[0].Dir) != "broken" || failures[0].Plugin != "local" {
+		t.Fatalf("expected one failure for broken, got %+v", failures)
+	}
+	if !strings.Contains(failures[0].Err.Error(), "frontmatter") {
+		t.Errorf("expected a frontmatter error, got %v", failures[0].Err)
+	}
+}
+
+func TestDiscoverPlugin(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "my-plugin")
+	for path, content := range map[string]string{
+		".claude-plugin/plugin.json":   `{"name": "shipit", "version": "0.3.0"}`,
+		"skills/deploy/SKILL.md":       "---\nname: deploy\ndescription: Ship it.\n---\nBody.\n",
+		"skills/broken/SKILL.md":       "---\nname: [oops\n---\n",
+		"skills/not-a-skill/README.md": "nothing here",
+	} {
+		full := filepath.Join(repo, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	skills, failures, err := DiscoverPlugin(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skills) != 1 || skills[0].ID() != "shipit:deploy" || skills[0].PluginVersion != "0.3.0" {
+		t.Errorf("expected shipit:deploy at 0.3.0, got %+v", skills)
+	}
+	if len(failures) != 1 || failures[0].Plugin != "shipit" {
+		t.Errorf("expected the broken skill reported, got %+v", failures)
+	}
+
+	// Without a manifest the directory names the plugin.
+	if err := os.Remove(filepath.Join(repo, ".claude-plugin", "plugin.json")); err != nil {
+		t.Fatal(err)
+	}
+	skills, _, err = DiscoverPlugin(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skills) != 1 || skills[0].Plugin != "my-plugin" {
+		t.Errorf("expected the directory name as plugin, got %+v", skills)
+	}
+
+	if _, _, err := DiscoverPlugin(filepath.Join(repo, "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestManifestSkillsPaths(t *testing.T) {
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		".claude-plugin/plugin.json": `{
+  "name": "tools",
+  "version": "2.1.0",
+  "description": "Team tooling",
+  "author": {"name": "Jane Doe", "email": "jane@example.com"},
+  "repository": {"type": "git", "url": "https://github.com/example/tools"},
+  "license": "MIT",
+  "skills": ["./extra", "./single", "../outside"],
+  "hooks": {"PreToolUse": []}
+}`,
+		"skills/base/SKILL.md":  "---\nname: base\ndescription: Base.\n---\n",
+		"extra/more/SKILL.md":   "---\nname: more\ndescription: More.\n---\n",
+		"single/SKILL.md":       "---\nname: single\ndescription: Single.\n---\n",
+		"../outside/x/SKILL.md": "---\nname: outside\ndescription: Out.\n---\n",
+	} {
+		full := filepath.Join(root, "plugin", path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	skills, failures := discoverPluginSkills(filepath.Join(root, "plugin"), "tools", nil)
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures: %+v", failures)
+	}
+	var names []string
+	for _, s := range skills {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "base,more,single" {
+		t.Errorf("expected base, more and single, got %v", names)
+	}
+
+	m := skills[0].Manifest
+	if m == nil || skills[1].Manifest != m {
+		t.Fatal("expected the manifest shared by the plugin's skills")
+	}
+	if m.Author.String() != "Jane Doe <jane@example.com>" || m.Repository != "https://github.com/example/tools" || m.License != "MIT" {
+		t.Errorf("unexpected manifest: %+v", m)
+	}
+	if len(m.Hooks) != 0 {
+		t.Errorf("expected inline hooks to be ignored, got %v", m.Hooks)
+	}
+	if skills[0].PluginVersion != "2.1.0" {
+		t.Errorf("expected the manifest version, got %q", skills[0].PluginVersion)
+	}
+}
+
+func TestDiscoverMarketplaces(t *testing.T) {
+	tmpDir := t.TempDir()
+	plugins := map[string][]PluginInstance{}
+	for _, key := range []string{"superpowers@claude-plugins-official", "deploy@internal", "misc@unknown"} {
+		name, _, _ := strings.Cut(key, "@")
+		skillDir := filepath.Join(tmpDir, name, "skills", name)
+		if err := os.MkdirAll(skillDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("Body.\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		plugins[key] = []PluginInstance{{InstallPath: filepath.Join(tmpDir, name)}}
+	}
+	data, err := json.Marshal(InstalledPlugins{Version: 2, Plugins: plugins})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pluginsFile := filepath.Join(tmpDir, "installed_plugins.json")
+	if err := os.WriteFile(pluginsFile, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	registry := `{
+  "claude-plugins-official": {
+    "source": {"source": "github", "repo": "anthropics/claude-plugins-official"},
+    "installLocation": "/home/me/.claude/plugins/marketplaces/claude-plugins-official"
+  },
+  "internal": {
+    "source": {"source": "directory", "path": "/srv/plugins"},
+    "installLocation": "/srv/plugins"
+  }
+}`
+	if err := os.WriteFile(filepath.Join(tmpDir, MarketplacesFileName), []byte(registry), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	skills, err := Discover(pluginsFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPlugin := make(map[string]Skill)
+	for _, s := range skills {
+		byPlugin[s.Plugin] = s
+	}
+
+	official := byPlugin["superpowers"]
+	if official.Marketplace != "claude-plugins-official" || official.MarketplaceInfo == nil ||
+		official.MarketplaceInfo.Source != "https://github.com/anthropics/claude-plugins-official" || !official.MarketplaceInfo.Official() {
+		t.Errorf("unexpected official marketplace: %q %+v", official.Marketplace, official.MarketplaceInfo)
+	}
+	internal := byPlugin["deploy"]
+	if internal.MarketplaceInfo == nil || !internal.MarketplaceInfo.Local() || internal.MarketplaceInfo.Source != "/srv/plugins" || internal.MarketplaceInfo.Official() {
+		t.Errorf("unexpected internal marketplace: %+v", internal.MarketplaceInfo)
+	}
+	if misc := byPlugin["misc"]; misc.Marketplace != "unknown" || misc.MarketplaceInfo != nil {
+		t.Errorf("expected an unregistered marketplace to keep its name only, got %q %+v", misc.Marketplace, misc.MarketplaceInfo)
+	}
+}
+
+func TestDiscoverAvailable(t *testing.T) {
+	tmpDir := t.TempDir()
+	clone := filepath.Join(tmpDir, "marketplaces", "team")
+	for path, content := range map[string]string{
+		"installed_plugins.json": `{"version": 2, "plugins": {"deploy@team": [{"installPath": "/nowhere"}]}}`,
+		MarketplacesFileName:     `{"team": {"source": {"source": "git", "url": "https://git.example.com/team.git"}}}`,
+		"marketplaces/team/" + MarketplaceManifestPath: `{
+  "metadata": {"pluginRoot": "./plugins"},
+  "plugins": [
+    {"name": "review", "source": "review", "version": "1.2.0"},
+    {"name": "deploy", "source": "./plugins/deploy"},
+    {"name": "remote", "source": {"source": "github", "repo": "example/remote"}},
+    {"name": "escape", "source": "../../outside"}
+  ]
+}`,
+		"marketplaces/team/plugins/review/skills/pr/SKILL.md":   "---\nname: pr\ndescription: Review PRs.\n---\n",
+		"marketplaces/team/plugins/deploy/skills/ship/SKILL.md": "---\nname: ship\ndescription: Ship.\n---\n",
+		"outside/skills/x/SKILL.md":                             "---\nname: x\ndescription: X.\n---\n",
+	} {
+		full := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	skills, failures, err := DiscoverAvailable(filepath.Join(tmpDir, "installed_plugins.json"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 0 {
+		t.Errorf("unexpected failures: %+v", failures)
+	}
+	if len(skills) != 1 {
+		t.Fatalf("expected only the uninstalled local plugin's skill, got %+v", skills)
+	}
+	s := skills[0]
+	if s.ID() != "review:pr" || !s.Available || s.Marketplace != "team" || s.PluginVersion != "1.2.0" ||
+		s.MarketplaceInfo == nil || s.MarketplaceInfo.Source != "https://git.example.com/team.git" {
+		t.Errorf("unexpected available skill: %+v", s)
+	}
+	if !strings.HasPrefix(s.FilePath, clone) {
+		t.Errorf("expected the skill read from the clone, got %s", s.FilePath)
+	}
+}
+
+func TestDiscoverCached(t *testing.T) {
+	tmpDir := t.TempDir()
+	pluginsFile := filepath.Join(tmpDir, "installed_plugins.json")
+	if err := os.WriteFile(pluginsFile, []byte(`{"version": 2, "plugins": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	skillsDir := filepath.Join(tmpDir, "skills")
+	skillFile := filepath.Join(skillsDir, "notes", "SKILL.md")
+	script := filepath.Join(skillsDir, "notes", "run")
+	if err := os.MkdirAll(filepath.Dir(skillFile), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, content string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cachePath := filepath.Join(tmpDir, "skillex", CacheFileName)
+	local := []LocalSkillsDir{{Path: skillsDir, Name: "local"}}
+	discover := func() Skill {
+		t.Helper()
+		cache := OpenCache(cachePath)
+		skills, _, err := DiscoverCached(pluginsFile, local, cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.Save(); err != nil {
+			t.Fatal(err)
+		}
+		if len(skills) != 1 {
+			t.Fatalf("expected 1 skill, got %d", len(skills))
+		}
+		return skills[0]
+	}
+
+	mtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	write(skillFile, "---\ndescription: First.\n---\nBody one.\n", mtime)
+	write(script, "#!/bin/sh\n", mtime)
+	if s := discover(); s.Description != "First." || len(s.Files) != 1 || s.Files[0].Type != FileScript {
+		t.Fatalf("first run: unexpected skill %+v", s)
+	}
+
+	// Same size and mtime: the whole parsed skill comes from the cache,
+	// frontmatter and body alike, and the bundled file keeps its type.
+	write(skillFile, "---\ndescription: Other.\n---\nBody two.\n", mtime)
+	write(script, "plain\n\n\n\n\n", mtime)
+	s := discover()
+	if s.Description != "First." || s.Frontmatter != "description: First." || s.Content != "Body one." {
+		t.Errorf("expected the cached skill, got %+v", s)
+	}
+	if s.Files[0].Type != FileScript {
+		t.Errorf("expected the cached file type, got %v", s.Files[0].Type)
+	}
+
+	write(skillFile, "---\ndescription: Second.\n---\nBody two.\n", mtime.Add(time.Second))
+	write(script, "plain\n", mtime.Add(time.Second))
+	s = discover()
+	if s.Description != "Second." || s.Frontmatter != "description: Second." || s.Content != "Body two." {
+		t.Errorf("expected a changed SKILL.md to be parsed again, got %+v", s)
+	}
+	if s.Files[0].Type != FileText {
+		t.Errorf("expected a changed file to be sniffed again, got %v", s.Files[0].Type)
+	}
+
+	// Entries of files that are gone are dropped on save.
+	if err := os.RemoveAll(skillsDir); err != nil {
+		t.Fatal(err)
+	}
+	cache := OpenCache(cachePath)
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if reopened := OpenCache(cachePath); len(reopened.skills) != 0 || len(reopened.files) != 0 {
+		t.Errorf("expected the removed skill's entries dropped, got %v and %v", reopened.skills, reopened.files)
+	}
+}
+
+// writeInstall lays out plugins × perPlugin skills as installed plugins and
+// returns the installed_plugins.json path.
+func writeInstall(b *testing.B, plugins, perPlugin int) string {
+	b.Helper()
+	root := b.TempDir()
+	installed := map[string][]map[string]string{}
+	body := strings.Repeat("Follow these steps carefully before changing any code.\n", 40)
+	for p := range plugins {
+		name := fmt.Sprintf("plugin-%03d", p)
+		dir := filepath.Join(root, "cache", name)
+		installed[name+"@bench"] = []map[string]string{{"scope": "user", "installPath": dir, "version": "1.0.0"}}
+		for s := range perPlugin {
+			skillDir := filepath.Join(dir, "skills", fmt.Sprintf("skill-%03d", s))
+			if err := os.MkdirAll(filepath.Join(skillDir, "scripts"), 0o755); err != nil {
+				b.Fatal(err)
+			}
+			content := fmt.Sprintf("---\nname: skill-%03d\ndescription: Use when working on area %d of %s.\nallowed-tools: Read, Bash(git log:*)\n---\n\n%s", s, s, name, body)
+			if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(content), 0o644); err != nil {
+				b.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(skillDir, "scripts", "run.sh"), []byte("#!/bin/sh\necho ok\n"), 0o755); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	data, err := json.Marshal(map[string]any{"version": 2, "plugins": installed})
+	if err != nil {
+		b.Fatal(err)
+	}
+	pluginsFile := filepath.Join(root, "installed_plugins.json")
+	if err := os.WriteFile(pluginsFile, data, 0o644); err != nil {
+		b.Fatal(err)
+	}
+	return pluginsFile
+}
+
+// benchmarkDiscover discovers 2000 skills in 40 plugins with the given
+// number of readers, optionally from a warm cache.
+func benchmarkDiscover(b *testing.B, workers int, cached bool) {
+	pluginsFile := writeInstall(b, 40, 50)
+	defer func(n int) { readWorkers = n }(readWorkers)
+	readWorkers = workers
+
+	cachePath := filepath.Join(b.TempDir(), CacheFileName)
+	if cached {
+		cache := OpenCache(cachePath)
+		if _, _, err := DiscoverCached(pluginsFile, nil, cache); err != nil {
+			b.Fatal(err)
+		}
+		if err := cache.Save(); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+	for b.Loop() {
+		var cache *Cache
+		if cached {
+			cache = OpenCache(cachePath)
+		}
+		skills, _, err := DiscoverCached(pluginsFile, nil, cache)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(skills) != 2000 {
+			b.Fatalf("expected 2000 skills, got %d", len(skills))
+		}
+	}
+}
+
+func BenchmarkDiscoverSerial(b *testing.B)   { benchmarkDiscover(b, 1, false) }
+func BenchmarkDiscoverParallel(b *testing.B) { benchmarkDiscover(b, 2*runtime.GOMAXPROCS(0), false) }
+func BenchmarkDiscoverCached(b *testing.B)   { benchmarkDiscover(b, 2*runtime.GOMAXPROCS(0), true) }
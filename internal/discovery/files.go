@@ -0,0 +1,133 @@
+package discovery
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileType classifies a file bundled alongside SKILL.md.
+type FileType int
+
+const (
+	// FileText is a readable text file such as a reference document or template.
+	FileText FileType = iota
+	// FileScript is a text file meant to be executed: it has a shebang line or a
+	// well-known script extension.
+	FileScript
+	// FileBinary is any file containing NUL bytes in its first block.
+	FileBinary
+)
+
+func (t FileType) String() string {
+	switch t {
+	case FileScript:
+		return "script"
+	case FileBinary:
+		return "binary"
+	default:
+		return "text"
+	}
+}
+
+// SkillFile describes one supporting file inside a skill directory.
+type SkillFile struct {
+	// Path is relative to the skill directory and always uses forward slashes.
+	Path       string
+	Size       int64
+	Type       FileType
+	Executable bool
+}
+
+// sniffLen is how many leading bytes are read to classify a file.
+const sniffLen = 512
+
+// MaxFiles caps how many bundled files are listed per skill, so a skill that
+// ships a whole project does not stall discovery.
+const MaxFiles = 500
+
+// dependencyDirs hold installed packages and build output rather than files
+// a skill bundles on purpose, so they are not listed. Hidden folders are
+// skipped as well.
+var dependencyDirs = map[string]bool{"node_modules": true, "vendor": true, "venv": true, "__pycache__": true}
+
+var scriptExts = map[string]bool{
+	".sh": true, ".bash": true, ".zsh": true, ".fish": true,
+	".py": true, ".rb": true, ".pl": true, ".js": true, ".mjs": true,
+	".cjs": true, ".ts": true, ".ps1": true, ".bat": true, ".cmd": true,
+}
+
+// inventoryFiles lists the regular files under dir except the skill file
+// itself, sorted by path. Hidden and dependency folders are skipped, and
+// listing stops after MaxFiles files, reporting truncated. File types are
+// taken from cache when the file has not changed since.
+func inventoryFiles(dir string, cache *Cache) (files []SkillFile, truncated bool) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || dependencyDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "SKILL.md" || rel == "SKILL.md.disabled" {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		if len(files) == MaxFiles {
+			truncated = true
+			return fs.SkipAll
+		}
+		files = append(files, SkillFile{
+			Path:       rel,
+			Size:       info.Size(),
+			Type:       cache.fileType(path, info),
+			Executable: info.Mode().Perm()&0o111 != 0,
+		})
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, truncated
+}
+
+// classifyFile sniffs the head of a file to tell text, scripts and binaries apart.
+func classifyFile(path string) FileType {
+	f, err := os.Open(path)
+	if err != nil {
+		return FileText
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return FileText
+	}
+	head = head[:n]
+
+	switch {
+	case bytes.IndexByte(head, 0) != -1:
+		return FileBinary
+	case bytes.HasPrefix(head, []byte("#!")):
+		return FileScript
+	case scriptExts[strings.ToLower(filepath.Ext(path))]:
+		return FileScript
+	default:
+		return FileText
+	}
+}
@@ -0,0 +1,164 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// InstalledPlugins is Claude Code's installed_plugins.json. Keys skillex does
+// not know are kept, so a file read and encoded again loses nothing.
+type InstalledPlugins struct {
+	Version int
+	// Plugins maps a key, "plugin@marketplace", to its installations; the
+	// first one is the one in use.
+	Plugins map[string][]PluginInstance
+
+	raw map[string]json.RawMessage
+}
+
+// PluginInstance is one installation of a plugin.
+type PluginInstance struct {
+	// Scope is user, project or local; ProjectPath is the project of the
+	// latter two.
+	Scope       string
+	ProjectPath string
+	InstallPath string
+	Version     string
+	// InstalledAt and LastUpdated are ISO 8601 timestamps, kept as written.
+	InstalledAt  string
+	LastUpdated  string
+	GitCommitSha string
+	IsLocal      bool
+
+	raw map[string]json.RawMessage
+}
+
+// ReadInstalled reads installed_plugins.json.
+func ReadInstalled(pluginsFile string) (*InstalledPlugins, error) {
+	data, err := os.ReadFile(pluginsFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading plugins file: %w", err)
+	}
+	var installed InstalledPlugins
+	if err := json.Unmarshal(data, &installed); err != nil {
+		return nil, fmt.Errorf("parsing plugins file: %w", err)
+	}
+	return &installed, nil
+}
+
+// Encode returns the file content for p, indented as Claude Code writes it.
+func (p *InstalledPlugins) Encode() ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func (p *InstalledPlugins) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &p.raw); err != nil {
+		return err
+	}
+	return decodeFields(p.raw, map[string]any{"version": &p.Version, "plugins": &p.Plugins})
+}
+
+func (p InstalledPlugins) MarshalJSON() ([]byte, error) {
+	return encodeObject(p.raw, []field{
+		{"version", p.Version, true},
+		{"plugins", p.Plugins, true},
+	})
+}
+
+func (i *PluginInstance) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &i.raw); err != nil {
+		return err
+	}
+	return decodeFields(i.raw, map[string]any{
+		"scope":        &i.Scope,
+		"projectPath":  &i.ProjectPath,
+		"installPath":  &i.InstallPath,
+		"version":      &i.Version,
+		"installedAt":  &i.InstalledAt,
+		"lastUpdated":  &i.LastUpdated,
+		"gitCommitSha": &i.GitCommitSha,
+		"isLocal":      &i.IsLocal,
+	})
+}
+
+func (i PluginInstance) MarshalJSON() ([]byte, error) {
+	return encodeObject(i.raw, []field{
+		{"scope", i.Scope, i.Scope != ""},
+		{"projectPath", i.ProjectPath, i.ProjectPath != ""},
+		{"installPath", i.InstallPath, true},
+		{"version", i.Version, i.Version != ""},
+		{"installedAt", i.InstalledAt, i.InstalledAt != ""},
+		{"lastUpdated", i.LastUpdated, i.LastUpdated != ""},
+		{"gitCommitSha", i.GitCommitSha, i.GitCommitSha != ""},
+		{"isLocal", i.IsLocal, i.IsLocal},
+	})
+}
+
+// field is a known key of a JSON object. It is written when set, or when
+// the object it was read from had it.
+type field struct {
+	key   string
+	value any
+	set   bool
+}
+
+// decodeFields decodes the known keys present in raw into their targets.
+func decodeFields(raw map[string]json.RawMessage, targets map[string]any) error {
+	for key, target := range targets {
+		if v, ok := raw[key]; ok {
+			if err := json.Unmarshal(v, target); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// encodeObject writes the known fields in order, then the keys of raw that
+// are not known, sorted.
+func encodeObject(raw map[string]json.RawMessage, fields []field) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	write := func(key string, value []byte) {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.key] = true
+		if _, had := raw[f.key]; !f.set && !had {
+			continue
+		}
+		v, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		write(f.key, v)
+	}
+
+	var extra []string
+	for key := range raw {
+		if !known[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		write(key, raw[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
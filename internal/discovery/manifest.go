@@ -0,0 +1,142 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestPath is where a plugin keeps its manifest, relative to its root.
+const ManifestPath = ".claude-plugin/plugin.json"
+
+// Manifest is a plugin's .claude-plugin/plugin.json.
+type Manifest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Version     string   `json:"version"`
+	Author      Author   `json:"author"`
+	Homepage    string   `json:"homepage"`
+	Repository  urlField `json:"repository"`
+	License     string   `json:"license"`
+	Keywords    []string `json:"keywords"`
+
+	// Component paths declared in the manifest, relative to the plugin
+	// root. They supplement the default folders rather than replace them.
+	// Inline hook and MCP server definitions are not paths and are left out.
+	Commands   pathList `json:"commands"`
+	Agents     pathList `json:"agents"`
+	Skills     pathList `json:"skills"`
+	Hooks      pathList `json:"hooks"`
+	MCPServers pathList `json:"mcpServers"`
+}
+
+// Author is the plugin's maintainer, written either as an object or as a
+// plain name.
+type Author struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	URL   string `json:"url"`
+}
+
+func (a *Author) UnmarshalJSON(data []byte) error {
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		*a = Author{Name: name}
+		return nil
+	}
+	type plain Author
+	return json.Unmarshal(data, (*plain)(a))
+}
+
+// String returns the author as "Name <email>", falling back to the URL.
+func (a Author) String() string {
+	s := a.Name
+	switch {
+	case a.Email != "":
+		s = strings.TrimSpace(s + " <" + a.Email + ">")
+	case a.URL != "" && s == "":
+		s = a.URL
+	}
+	return s
+}
+
+// urlField decodes a URL given as a string or as an npm-style object with a
+// url key, as repository often is.
+type urlField string
+
+func (u *urlField) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*u = urlField(s)
+		return nil
+	}
+	var obj struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*u = urlField(obj.URL)
+	return nil
+}
+
+// pathList decodes a component path declared as one string or a list of
+// strings. Anything else, such as an inline hooks object, decodes to nil.
+type pathList []string
+
+func (p *pathList) UnmarshalJSON(data []byte) error {
+	var one string
+	if json.Unmarshal(data, &one) == nil {
+		*p = pathList{one}
+		return nil
+	}
+	var many []string
+	if json.Unmarshal(data, &many) == nil {
+		*p = many
+		return nil
+	}
+	*p = nil
+	return nil
+}
+
+// ReadManifest reads the manifest of the plugin rooted at dir. A plugin
+// without one yields nil and no error.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ManifestPath, err)
+	}
+	return &m, nil
+}
+
+// skillDirs returns the folders holding the skills of the plugin rooted at
+// dir: skills/ and any custom skills paths the manifest declares. Paths that
+// leave the plugin root are ignored.
+func (m *Manifest) skillDirs(dir string) []string {
+	dirs := []string{filepath.Join(dir, "skills")}
+	if m == nil {
+		return dirs
+	}
+	seen := map[string]bool{dirs[0]: true}
+	for _, p := range m.Skills {
+		rel := filepath.Clean(filepath.FromSlash(p))
+		if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		full := filepath.Join(dir, rel)
+		if !seen[full] {
+			seen[full] = true
+			dirs = append(dirs, full)
+		}
+	}
+	return dirs
+}
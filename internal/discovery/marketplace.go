@@ -0,0 +1,77 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MarketplacesFileName is Claude Code's registry of added marketplaces,
+// kept next to installed_plugins.json.
+const MarketplacesFileName = "known_marketplaces.json"
+
+// Marketplace is a plugin marketplace known to Claude Code.
+type Marketplace struct {
+	Name string
+	// Kind is how Claude Code fetches the marketplace: github, git, url,
+	// directory or file.
+	Kind string
+	// Source is where the marketplace comes from: a git URL, or a local
+	// path for directory and file marketplaces.
+	Source string
+	// InstallLocation is Claude Code's local copy of the marketplace.
+	InstallLocation string
+}
+
+// Local reports whether the marketplace is a path on this machine rather
+// than a remote repository.
+func (m Marketplace) Local() bool {
+	return m.Kind == "directory" || m.Kind == "file"
+}
+
+// Official reports whether the marketplace is published by Anthropic.
+func (m Marketplace) Official() bool {
+	return m.Kind == "github" && strings.HasPrefix(strings.ToLower(m.Source), "https://github.com/anthropics/")
+}
+
+type knownMarketplace struct {
+	Source struct {
+		Source string `json:"source"`
+		Repo   string `json:"repo"`
+		URL    string `json:"url"`
+		Path   string `json:"path"`
+	} `json:"source"`
+	InstallLocation string `json:"installLocation"`
+}
+
+// LoadMarketplaces reads the marketplace registry at path, keyed by
+// marketplace name. A missing registry yields nil and no error.
+func LoadMarketplaces(path string) (map[string]Marketplace, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var known map[string]knownMarketplace
+	if err := json.Unmarshal(data, &known); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", MarketplacesFileName, err)
+	}
+
+	markets := make(map[string]Marketplace, len(known))
+	for name, k := range known {
+		m := Marketplace{Name: name, Kind: k.Source.Source, InstallLocation: k.InstallLocation}
+		switch {
+		case k.Source.Repo != "":
+			m.Source = "https://github.com/" + k.Source.Repo
+		case k.Source.URL != "":
+			m.Source = k.Source.URL
+		default:
+			m.Source = k.Source.Path
+		}
+		markets[name] = m
+	}
+	return markets, nil
+}
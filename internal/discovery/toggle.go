@@ -0,0 +1,211 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// ErrConflict matches every *ConflictError with errors.Is.
+var ErrConflict = errors.New("skill conflict")
+
+// ConflictKind describes how a skill's files disagree with what was loaded.
+type ConflictKind int
+
+const (
+	// ConflictBothExist means SKILL.md and SKILL.md.disabled both exist, so
+	// the skill's state is ambiguous.
+	ConflictBothExist ConflictKind = iota + 1
+	// ConflictMissing means neither file exists any more.
+	ConflictMissing
+	// ConflictMoved means the file was enabled or disabled outside skillex.
+	ConflictMoved
+	// ConflictModified means the file was edited or replaced since loading.
+	ConflictModified
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case ConflictBothExist:
+		return "both files exist"
+	case ConflictMissing:
+		return "missing"
+	case ConflictMoved:
+		return "renamed"
+	case ConflictModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// ConflictError reports that a skill's files on disk no longer match the
+// loaded skill, so changing them could lose data.
+type ConflictError struct {
+	Kind ConflictKind
+	// Dir is the skill's directory.
+	Dir string
+}
+
+func (e *ConflictError) Error() string {
+	switch e.Kind {
+	case ConflictBothExist:
+		return fmt.Sprintf("both SKILL.md and SKILL.md.disabled exist in %s", e.Dir)
+	case ConflictMissing:
+		return fmt.Sprintf("skill file no longer exists in %s", e.Dir)
+	case ConflictMoved:
+		return fmt.Sprintf("skill in %s was enabled or disabled outside skillex", e.Dir)
+	case ConflictModified:
+		return fmt.Sprintf("skill file in %s changed since it was loaded", e.Dir)
+	}
+	return "skill conflict in " + e.Dir
+}
+
+// Is makes errors.Is(err, ErrConflict) true for every ConflictError.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// skillPaths returns the enabled and disabled skill file paths in dir.
+func skillPaths(dir string) (enabled, disabled string) {
+	enabled = filepath.Join(dir, "SKILL.md")
+	return enabled, enabled + ".disabled"
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Verify checks that the skill's files on disk still match the loaded skill
+// and returns a *ConflictError if not. Skills without a recorded ModTime
+// are only checked for existence.
+func Verify(skill *Skill) error {
+	dir := skill.Dir()
+	enabledPath, disabledPath := skillPaths(dir)
+	if fileExists(enabledPath) && fileExists(disabledPath) {
+		return &ConflictError{Kind: ConflictBothExist, Dir: dir}
+	}
+
+	info, err := os.Stat(skill.FilePath)
+	if os.IsNotExist(err) {
+		if fileExists(enabledPath) || fileExists(disabledPath) {
+			return &ConflictError{Kind: ConflictMoved, Dir: dir}
+		}
+		return &ConflictError{Kind: ConflictMissing, Dir: dir}
+	}
+	if err != nil {
+		return err
+	}
+	if !skill.ModTime.IsZero() && (!info.ModTime().Equal(skill.ModTime) || info.Size() != skill.Size) {
+		return &ConflictError{Kind: ConflictModified, Dir: dir}
+	}
+	return nil
+}
+
+// ToggleSkill renames a skill's file between SKILL.md and SKILL.md.disabled,
+// toggling its visibility to Claude Code. It updates FilePath and Enabled
+// in place. The files are verified first, and the rename never replaces an
+// existing file; conflicts are returned as *ConflictError.
+func ToggleSkill(skill *Skill) error {
+	if err := Verify(skill); err != nil {
+		return err
+	}
+
+	enabledPath, disabledPath := skillPaths(skill.Dir())
+	from, to, verb := enabledPath, disabledPath, "disabling"
+	if !skill.Enabled {
+		from, to, verb = disabledPath, enabledPath, "enabling"
+	}
+	if err := state.MoveNoReplace(from, to); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return &ConflictError{Kind: ConflictBothExist, Dir: skill.Dir()}
+		}
+		return fmt.Errorf("%s skill: %w", verb, err)
+	}
+	skill.FilePath = to
+	skill.Enabled = !skill.Enabled
+	return nil
+}
+
+// Resolution is a way out of a conflict, chosen by the user.
+type Resolution int
+
+const (
+	// KeepEnabled keeps SKILL.md and sets SKILL.md.disabled aside.
+	KeepEnabled Resolution = iota
+	// KeepDisabled keeps SKILL.md.disabled and sets SKILL.md aside.
+	KeepDisabled
+	// Reload accepts whatever is on disk and re-reads the skill.
+	Reload
+)
+
+// ConflictSuffix is appended to a skill file set aside by ResolveConflict.
+const ConflictSuffix = ".conflict"
+
+// ResolveConflict applies resolution to a skill and reloads it from disk.
+// A file set aside is renamed with ConflictSuffix rather than deleted, and
+// that rename is returned so the caller can journal it; from is empty when
+// nothing was renamed. When both names are links to the same file, left by
+// an interrupted toggle, the extra name is removed since nothing is lost.
+func ResolveConflict(skill *Skill, resolution Resolution) (from, to string, err error) {
+	enabledPath, disabledPath := skillPaths(skill.Dir())
+	switch resolution {
+	case KeepEnabled:
+		from = disabledPath
+	case KeepDisabled:
+		from = enabledPath
+	}
+
+	if from != "" {
+		if !fileExists(enabledPath) || !fileExists(disabledPath) {
+			return "", "", fmt.Errorf("no conflict to resolve in %s", skill.Dir())
+		}
+		if sameFile(enabledPath, disabledPath) {
+			if err := os.Remove(from); err != nil {
+				return "", "", fmt.Errorf("resolving conflict: %w", err)
+			}
+			from = ""
+		} else {
+			to = from + ConflictSuffix
+			if err := state.MoveNoReplace(from, to); err != nil {
+				return "", "", fmt.Errorf("setting aside %s: %w", from, err)
+			}
+		}
+	}
+
+	fresh, ok := loadSkill(skill.Dir(), skill.Plugin)
+	if !ok {
+		return from, to, &ConflictError{Kind: ConflictMissing, Dir: skill.Dir()}
+	}
+	*skill = fresh
+	return from, to, nil
+}
+
+func sameFile(a, b string) bool {
+	ia, errA := os.Stat(a)
+	ib, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(ia, ib)
+}
+
+// Restat re-reads a skill's enabled state from disk, following a rename made
+// since discovery, for example by undo. SKILL.md wins when both files exist,
+// matching discovery.
+func Restat(skill *Skill) error {
+	enabledPath, disabledPath := skillPaths(skill.Dir())
+	path, enabled := enabledPath, true
+	info, err := os.Stat(enabledPath)
+	if err != nil {
+		path, enabled = disabledPath, false
+		if info, err = os.Stat(disabledPath); err != nil {
+			return &ConflictError{Kind: ConflictMissing, Dir: skill.Dir()}
+		}
+	}
+	skill.FilePath, skill.Enabled = path, enabled
+	skill.ModTime, skill.Size = info.ModTime(), info.Size()
+	skill.Conflict = enabled && fileExists(disabledPath)
+	return nil
+}
@@ -0,0 +1,146 @@
+package discovery
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// loadTestSkill writes files (name to content) into a fresh skill directory
+// and loads it.
+func loadTestSkill(t *testing.T, files map[string]string) Skill {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "my-skill")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	skill, ok := loadSkill(dir, "local")
+	if !ok {
+		t.Fatal("expected skill to load")
+	}
+	return skill
+}
+
+func conflictKind(err error) ConflictKind {
+	var conflict *ConflictError
+	if errors.As(err, &conflict) {
+		return conflict.Kind
+	}
+	return 0
+}
+
+func TestToggleDetectsConflicts(t *testing.T) {
+	t.Run("both exist", func(t *testing.T) {
+		skill := loadTestSkill(t, map[string]string{"SKILL.md": "a", "SKILL.md.disabled": "b"})
+		if !skill.Conflict {
+			t.Error("expected discovery to flag the conflict")
+		}
+		err := ToggleSkill(&skill)
+		if conflictKind(err) != ConflictBothExist || !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ConflictBothExist, got %v", err)
+		}
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		skill := loadTestSkill(t, map[string]string{"SKILL.md": "a"})
+		if err := os.WriteFile(skill.FilePath, []byte("edited"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ToggleSkill(&skill); conflictKind(err) != ConflictModified {
+			t.Errorf("expected ConflictModified, got %v", err)
+		}
+		if !skill.Enabled || !fileExists(skill.FilePath) {
+			t.Error("expected skill left untouched")
+		}
+	})
+
+	t.Run("moved", func(t *testing.T) {
+		skill := loadTestSkill(t, map[string]string{"SKILL.md": "a"})
+		if err := os.Rename(skill.FilePath, skill.FilePath+".disabled"); err != nil {
+			t.Fatal(err)
+		}
+		if err := ToggleSkill(&skill); conflictKind(err) != ConflictMoved {
+			t.Errorf("expected ConflictMoved, got %v", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		skill := loadTestSkill(t, map[string]string{"SKILL.md": "a"})
+		if err := os.Remove(skill.FilePath); err != nil {
+			t.Fatal(err)
+		}
+		if err := ToggleSkill(&skill); conflictKind(err) != ConflictMissing {
+			t.Errorf("expected ConflictMissing, got %v", err)
+		}
+	})
+}
+
+func TestToggleKeepsLoadedStateValid(t *testing.T) {
+	skill := loadTestSkill(t, map[string]string{"SKILL.md": "a"})
+	// Toggling twice must not trip the modification check on its own rename.
+	for i := 0; i < 2; i++ {
+		if err := ToggleSkill(&skill); err != nil {
+			t.Fatalf("toggle %d: %v", i, err)
+		}
+	}
+	if !skill.Enabled || fileExists(skill.FilePath+".disabled") {
+		t.Errorf("expected skill enabled with a single file, got %+v", skill)
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	skill := loadTestSkill(t, map[string]string{"SKILL.md": "enabled", "SKILL.md.disabled": "disabled"})
+	enabledPath, disabledPath := skillPaths(skill.Dir())
+
+	from, to, err := ResolveConflict(&skill, KeepDisabled)
+	if err != nil {
+		t.Fatalf("ResolveConflict() error: %v", err)
+	}
+	if from != enabledPath || to != enabledPath+ConflictSuffix {
+		t.Errorf("expected SKILL.md set aside, got %s -> %s", from, to)
+	}
+	if skill.Enabled || skill.Conflict || skill.FilePath != disabledPath {
+		t.Errorf("expected reloaded disabled skill, got %+v", skill)
+	}
+	if data, _ := os.ReadFile(to); string(data) != "enabled" {
+		t.Errorf("expected set-aside file to keep its content, got %q", data)
+	}
+}
+
+func TestResolveInterruptedToggle(t *testing.T) {
+	skill := loadTestSkill(t, map[string]string{"SKILL.md": "a"})
+	enabledPath, disabledPath := skillPaths(skill.Dir())
+	// An interrupted toggle leaves a second link to the same file.
+	if err := os.Link(enabledPath, disabledPath); err != nil {
+		t.Skipf("hard links unsupported: %v", err)
+	}
+
+	from, _, err := ResolveConflict(&skill, KeepEnabled)
+	if err != nil {
+		t.Fatalf("ResolveConflict() error: %v", err)
+	}
+	if from != "" || fileExists(disabledPath) || fileExists(disabledPath+ConflictSuffix) {
+		t.Error("expected the extra link removed without a set-aside file")
+	}
+}
+
+func TestRestatRefreshesLoadedState(t *testing.T) {
+	skill := loadTestSkill(t, map[string]string{"SKILL.md": "a"})
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(skill.FilePath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restat(&skill); err != nil {
+		t.Fatal(err)
+	}
+	if err := ToggleSkill(&skill); err != nil {
+		t.Errorf("expected toggle to succeed after Restat, got %v", err)
+	}
+}
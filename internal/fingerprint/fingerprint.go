@@ -0,0 +1,204 @@
+// Package fingerprint remembers what every skill looked like on the last
+// launch, so skillex can point out skills that are new, changed or removed
+// since then. Plugin updates silently reword skills, which changes how they
+// activate and how much of the description budget they take.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// FileName is the fingerprint file's name inside the skillex directory.
+const FileName = "fingerprints.json"
+
+// Fingerprint identifies a skill's text and the plugin release it came
+// from.
+type Fingerprint struct {
+	// Hash is the SHA-256 of the frontmatter and body.
+	Hash          string `json:"hash"`
+	PluginVersion string `json:"pluginVersion,omitempty"`
+	GitCommitSha  string `json:"gitCommitSha,omitempty"`
+}
+
+// Of returns the skill's fingerprint.
+func Of(s discovery.Skill) Fingerprint {
+	sum := sha256.Sum256([]byte(s.Frontmatter + "\x00" + s.Content))
+	return Fingerprint{Hash: hex.EncodeToString(sum[:]), PluginVersion: s.PluginVersion, GitCommitSha: s.GitCommitSha}
+}
+
+// Set is the content of the fingerprint file: one fingerprint per skill ID,
+// taken at one launch.
+type Set struct {
+	TakenAt time.Time              `json:"takenAt"`
+	Skills  map[string]Fingerprint `json:"skills"`
+}
+
+// Take fingerprints skills.
+func Take(skills []discovery.Skill, now time.Time) *Set {
+	set := &Set{TakenAt: now, Skills: make(map[string]Fingerprint, len(skills))}
+	for _, s := range skills {
+		set.Skills[s.ID()] = Of(s)
+	}
+	return set
+}
+
+// Load reads the fingerprint file at path. A missing file yields nil and
+// no error: there is no previous launch to compare with.
+func Load(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading fingerprints: %w", err)
+	}
+	var set Set
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parsing fingerprints: %w", err)
+	}
+	return &set, nil
+}
+
+// Save writes the set atomically.
+func (s *Set) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding fingerprints: %w", err)
+	}
+	if err := state.WriteFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("writing fingerprints: %w", err)
+	}
+	return nil
+}
+
+// Status is how a skill differs from the previous launch.
+type Status int
+
+const (
+	Unchanged Status = iota
+	// New means the skill was not there on the previous launch.
+	New
+	// Changed means the frontmatter or body differ.
+	Changed
+)
+
+// String returns the badge text for the status.
+func (s Status) String() string {
+	switch s {
+	case New:
+		return "new"
+	case Changed:
+		return "changed"
+	}
+	return ""
+}
+
+// Update is a release change of an installed plugin.
+type Update struct {
+	Plugin   string
+	From, To string
+}
+
+// Report compares the current skills with the previous launch.
+type Report struct {
+	// Since is when the previous fingerprints were taken; zero on the first
+	// launch, when nothing is reported.
+	Since time.Time
+	// Status maps the IDs of new and changed skills to their status.
+	Status map[string]Status
+	// Removed lists the IDs of skills gone since, sorted.
+	Removed []string
+	// Updates lists plugins whose version or commit changed, by name.
+	Updates []Update
+}
+
+// Compare reports how skills differ from prev. A nil prev yields an empty
+// report.
+func Compare(prev *Set, skills []discovery.Skill) Report {
+	r := Report{Status: make(map[string]Status)}
+	if prev == nil {
+		return r
+	}
+	r.Since = prev.TakenAt
+
+	seen := make(map[string]bool)
+	updated := make(map[string]bool)
+	for _, s := range skills {
+		id := s.ID()
+		seen[id] = true
+		old, ok := prev.Skills[id]
+		if !ok {
+			r.Status[id] = New
+			continue
+		}
+		cur := Of(s)
+		if old.Hash != cur.Hash {
+			r.Status[id] = Changed
+		}
+		if (old.PluginVersion != cur.PluginVersion || old.GitCommitSha != cur.GitCommitSha) && !updated[s.Plugin] {
+			updated[s.Plugin] = true
+			u := Update{Plugin: s.Plugin, From: old.PluginVersion, To: cur.PluginVersion}
+			if u.From == u.To {
+				// Same version string, new commit.
+				u.From, u.To = short(old.GitCommitSha), short(cur.GitCommitSha)
+			}
+			r.Updates = append(r.Updates, u)
+		}
+	}
+	for id := range prev.Skills {
+		if !seen[id] {
+			r.Removed = append(r.Removed, id)
+		}
+	}
+	sort.Strings(r.Removed)
+	sort.Slice(r.Updates, func(i, j int) bool { return r.Updates[i].Plugin < r.Updates[j].Plugin })
+	return r
+}
+
+// Count returns the number of new and changed skills.
+func (r Report) Count() (added, changed int) {
+	for _, st := range r.Status {
+		if st == New {
+			added++
+		} else {
+			changed++
+		}
+	}
+	return added, changed
+}
+
+// Empty reports whether nothing changed since the previous launch.
+func (r Report) Empty() bool {
+	return len(r.Status) == 0 && len(r.Removed) == 0 && len(r.Updates) == 0
+}
+
+// short abbreviates a commit hash.
+func short(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// Refresh compares skills with the fingerprints at path and replaces them
+// with the current ones, so the next launch reports changes since now.
+func Refresh(path string, skills []discovery.Skill) (Report, error) {
+	prev, err := Load(path)
+	if err != nil {
+		return Report{Status: make(map[string]Status)}, err
+	}
+	r := Compare(prev, skills)
+	if err := Take(skills, time.Now()).Save(path); err != nil {
+		return r, err
+	}
+	return r, nil
+}
@@ -0,0 +1,49 @@
+package fingerprint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+func TestRefreshReportsChangesSinceLastLaunch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	skills := []discovery.Skill{
+		{Name: "a", Plugin: "p", Content: "one", PluginVersion: "1.0.0"},
+		{Name: "b", Plugin: "p", Content: "two", PluginVersion: "1.0.0"},
+		{Name: "gone", Plugin: "local", Content: "bye"},
+	}
+	r, err := Refresh(path, skills)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Empty() || !r.Since.IsZero() {
+		t.Fatalf("expected nothing reported on the first launch, got %+v", r)
+	}
+
+	skills[0].Content = "one, reworded"
+	skills[0].PluginVersion, skills[1].PluginVersion = "1.1.0", "1.1.0"
+	skills[2] = discovery.Skill{Name: "c", Plugin: "local", Content: "hi"}
+	r, err = Refresh(path, skills)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status["p:a"] != Changed || r.Status["local:c"] != New || r.Status["p:b"] != Unchanged {
+		t.Errorf("unexpected statuses %v", r.Status)
+	}
+	if len(r.Removed) != 1 || r.Removed[0] != "local:gone" {
+		t.Errorf("expected local:gone removed, got %v", r.Removed)
+	}
+	if len(r.Updates) != 1 || r.Updates[0] != (Update{Plugin: "p", From: "1.0.0", To: "1.1.0"}) {
+		t.Errorf("expected one plugin update, got %+v", r.Updates)
+	}
+	if added, changed := r.Count(); added != 1 || changed != 1 {
+		t.Errorf("expected 1 new and 1 changed, got %d %d", added, changed)
+	}
+
+	// The launch after that compares with the fingerprints just taken.
+	if r, _ = Refresh(path, skills); !r.Empty() {
+		t.Errorf("expected no changes on an unchanged launch, got %+v", r)
+	}
+}
@@ -0,0 +1,269 @@
+// Package install registers plugins in Claude Code's installed_plugins.json
+// and removes them again. It handles plugins that are on disk already, in a
+// local directory or a marketplace clone, and registers them where they are
+// rather than copying them.
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// BackupSuffix is appended to installed_plugins.json for the copy of the
+// previous content kept by Apply.
+const BackupSuffix = ".bak"
+
+// LocalMarketplace is the marketplace named in the key of plugins installed
+// from a directory when no other is given.
+const LocalMarketplace = "local"
+
+// Scopes lists the installation scopes Claude Code knows.
+var Scopes = []string{"user", "project", "local"}
+
+// timeFormat matches the timestamps Claude Code writes.
+const timeFormat = "2006-01-02T15:04:05.000Z"
+
+// Options describe an installation.
+type Options struct {
+	// Name overrides the plugin's own name, for marketplace plugins, which
+	// are keyed by the name their marketplace lists.
+	Name string
+	// Marketplace is the part of the key after "@"; LocalMarketplace when
+	// empty.
+	Marketplace string
+	// Version is used when the plugin's manifest names none.
+	Version string
+	// Scope is one of Scopes, user when empty. ProjectPath is required for
+	// the project and local scopes.
+	Scope       string
+	ProjectPath string
+	Now         time.Time
+}
+
+// Change is an edit of installed_plugins.json, computed but not yet written.
+// Before is nil when the file does not exist.
+type Change struct {
+	Key     string
+	Summary string
+	Before  []byte
+	After   []byte
+}
+
+// Install computes the change that registers the plugin in dir.
+func Install(pluginsFile, dir string, opts Options) (Change, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Change{}, err
+	}
+	manifest, err := discovery.ReadManifest(abs)
+	if err != nil {
+		return Change{}, err
+	}
+	if manifest == nil && !isDir(filepath.Join(abs, "skills")) {
+		return Change{}, fmt.Errorf("%s is not a plugin: no %s or skills folder", dir, discovery.ManifestPath)
+	}
+
+	scope := opts.Scope
+	if scope == "" {
+		scope = "user"
+	}
+	if !validScope(scope) {
+		return Change{}, fmt.Errorf("unknown scope %q (want %s)", scope, strings.Join(Scopes, ", "))
+	}
+	if scope != "user" && opts.ProjectPath == "" {
+		return Change{}, fmt.Errorf("the %s scope needs a project path", scope)
+	}
+
+	name := filepath.Base(abs)
+	version := opts.Version
+	if manifest != nil {
+		if manifest.Name != "" {
+			name = manifest.Name
+		}
+		if manifest.Version != "" {
+			version = manifest.Version
+		}
+	}
+	sha := gitHead(abs)
+	if version == "" {
+		version = "unknown"
+		if len(sha) >= 12 {
+			version = sha[:12]
+		}
+	}
+	if opts.Name != "" {
+		name = opts.Name
+	}
+	market := opts.Marketplace
+	if market == "" {
+		market = LocalMarketplace
+	}
+	key := name + "@" + market
+
+	installed, before, err := read(pluginsFile)
+	if err != nil {
+		return Change{}, err
+	}
+	if _, ok := installed.Plugins[key]; ok {
+		return Change{}, fmt.Errorf("%s is already installed", key)
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	stamp := now.UTC().Format(timeFormat)
+	inst := discovery.PluginInstance{
+		Scope:        scope,
+		InstallPath:  abs,
+		Version:      version,
+		InstalledAt:  stamp,
+		LastUpdated:  stamp,
+		GitCommitSha: sha,
+	}
+	if scope != "user" {
+		inst.ProjectPath = opts.ProjectPath
+	}
+	if installed.Plugins == nil {
+		installed.Plugins = make(map[string][]discovery.PluginInstance)
+	}
+	installed.Plugins[key] = []discovery.PluginInstance{inst}
+
+	after, err := installed.Encode()
+	if err != nil {
+		return Change{}, err
+	}
+	return Change{Key: key, Summary: fmt.Sprintf("install %s %s", key, version), Before: before, After: after}, nil
+}
+
+// Uninstall computes the change that removes a plugin, named by its key or,
+// when that is unambiguous, by its name alone. Only the entry in scope is
+// removed; for the project and local scopes, the one of projectPath. With
+// scope empty, the plugin must be installed in a single scope.
+func Uninstall(pluginsFile, name, scope, projectPath string) (Change, error) {
+	installed, before, err := read(pluginsFile)
+	if err != nil {
+		return Change{}, err
+	}
+	if before == nil {
+		return Change{}, fmt.Errorf("no plugins are installed")
+	}
+	if scope != "" && !validScope(scope) {
+		return Change{}, fmt.Errorf("unknown scope %q (want %s)", scope, strings.Join(Scopes, ", "))
+	}
+	if scope != "" && scope != "user" && projectPath == "" {
+		return Change{}, fmt.Errorf("the %s scope needs a project path", scope)
+	}
+
+	key := name
+	if _, ok := installed.Plugins[key]; !ok {
+		var matches []string
+		for k := range installed.Plugins {
+			if strings.HasPrefix(k, name+"@") {
+				matches = append(matches, k)
+			}
+		}
+		sort.Strings(matches)
+		switch len(matches) {
+		case 0:
+			return Change{}, fmt.Errorf("no installed plugin named %s", name)
+		case 1:
+			key = matches[0]
+		default:
+			return Change{}, fmt.Errorf("%s is ambiguous: %s", name, strings.Join(matches, ", "))
+		}
+	}
+
+	entries := installed.Plugins[key]
+	var kept []discovery.PluginInstance
+	switch {
+	case scope != "":
+		for _, inst := range entries {
+			if inst.Scope != scope || (scope != "user" && inst.ProjectPath != projectPath) {
+				kept = append(kept, inst)
+			}
+		}
+		if len(kept) == len(entries) {
+			if scope == "user" {
+				return Change{}, fmt.Errorf("%s is not installed in the user scope", key)
+			}
+			return Change{}, fmt.Errorf("%s is not installed in the %s scope of %s", key, scope, projectPath)
+		}
+	case len(entries) > 1:
+		where := make([]string, len(entries))
+		for i, inst := range entries {
+			where[i] = inst.Scope
+			if inst.ProjectPath != "" {
+				where[i] += " (" + inst.ProjectPath + ")"
+			}
+		}
+		return Change{}, fmt.Errorf("%s is installed in more than one scope: %s; choose one", key, strings.Join(where, ", "))
+	}
+	if len(kept) == 0 {
+		delete(installed.Plugins, key)
+	} else {
+		installed.Plugins[key] = kept
+	}
+
+	after, err := installed.Encode()
+	if err != nil {
+		return Change{}, err
+	}
+	summary := "uninstall " + key
+	if scope != "" {
+		summary += " from the " + scope + " scope"
+	}
+	return Change{Key: key, Summary: summary, Before: before, After: after}, nil
+}
+
+// Apply writes the change, after copying the current file to
+// pluginsFile+BackupSuffix.
+func Apply(pluginsFile string, c Change) error {
+	if c.Before != nil {
+		if err := state.WriteFileAtomic(pluginsFile+BackupSuffix, c.Before); err != nil {
+			return fmt.Errorf("backing up plugins file: %w", err)
+		}
+	}
+	if err := state.WriteFileAtomic(pluginsFile, c.After); err != nil {
+		return fmt.Errorf("writing plugins file: %w", err)
+	}
+	return nil
+}
+
+// read loads installed_plugins.json and its raw content. A missing file
+// yields an empty version 2 file and nil content.
+func read(pluginsFile string) (*discovery.InstalledPlugins, []byte, error) {
+	data, err := os.ReadFile(pluginsFile)
+	if os.IsNotExist(err) {
+		return &discovery.InstalledPlugins{Version: 2}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading plugins file: %w", err)
+	}
+	installed, err := discovery.ReadInstalled(pluginsFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return installed, data, nil
+}
+
+func validScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
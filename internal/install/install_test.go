@@ -0,0 +1,172 @@
+package install
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const existing = `{
+  "version": 2,
+  "plugins": {
+    "superpowers@claude-plugins-official": [
+      {
+        "scope": "user",
+        "installPath": "/home/me/.claude/plugins/cache/superpowers",
+        "version": "4.2.0",
+        "installedAt": "2026-02-09T08:43:14.746Z",
+        "lastUpdated": "2026-02-09T08:43:14.740Z",
+        "gitCommitSha": "abc123",
+        "futureField": {"nested": [1, 2]}
+      }
+    ]
+  },
+  "topLevelExtra": true
+}
+`
+
+func writePlugin(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func decode(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var v map[string]any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	return v
+}
+
+func TestInstallAndUninstall(t *testing.T) {
+	tmp := t.TempDir()
+	pluginsFile := filepath.Join(tmp, "installed_plugins.json")
+	if err := os.WriteFile(pluginsFile, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(tmp, "repo")
+	writePlugin(t, repo, map[string]string{
+		".git/HEAD":                         "ref: refs/heads/main\n",
+		".git/packed-refs":                  "# pack-refs with: peeled\n0123456789abcdef0123456789abcdef01234567 refs/heads/main\n",
+		"plugin/.claude-plugin/plugin.json": `{"name": "shipit", "version": "0.3.0"}`,
+		"plugin/skills/deploy/SKILL.md":     "---\nname: deploy\ndescription: Deploy.\n---\n",
+	})
+
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	c, err := Install(pluginsFile, filepath.Join(repo, "plugin"), Options{Marketplace: "team", Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Key != "shipit@team" || string(c.Before) != existing {
+		t.Fatalf("unexpected change: %s", c.Key)
+	}
+	if err := Apply(pluginsFile, c); err != nil {
+		t.Fatal(err)
+	}
+	if backup, err := os.ReadFile(pluginsFile + BackupSuffix); err != nil || string(backup) != existing {
+		t.Errorf("expected the previous file backed up, got %q (%v)", backup, err)
+	}
+
+	data, err := os.ReadFile(pluginsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := decode(t, data)
+	entry := got["plugins"].(map[string]any)["shipit@team"].([]any)[0].(map[string]any)
+	want := map[string]any{
+		"scope":        "user",
+		"installPath":  filepath.Join(repo, "plugin"),
+		"version":      "0.3.0",
+		"installedAt":  "2026-10-18T09:30:00.000Z",
+		"lastUpdated":  "2026-10-18T09:30:00.000Z",
+		"gitCommitSha": "0123456789abcdef0123456789abcdef01234567",
+	}
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("unexpected entry:\n got %v\nwant %v", entry, want)
+	}
+
+	if _, err := Install(pluginsFile, filepath.Join(repo, "plugin"), Options{Marketplace: "team"}); err == nil {
+		t.Error("expected installing twice to fail")
+	}
+
+	// Uninstalling by name restores the original content, unknown fields
+	// included.
+	c, err = Uninstall(pluginsFile, "shipit", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decode(t, c.After), decode(t, []byte(existing))) {
+		t.Errorf("expected a lossless round trip, got:\n%s", c.After)
+	}
+	if _, err := Uninstall(pluginsFile, "missing", "", ""); err == nil {
+		t.Error("expected an error for a plugin that is not installed")
+	}
+}
+
+func TestInstallRejectsNonPlugins(t *testing.T) {
+	tmp := t.TempDir()
+	pluginsFile := filepath.Join(tmp, "installed_plugins.json")
+	if _, err := Install(pluginsFile, tmp, Options{}); err == nil || !strings.Contains(err.Error(), "not a plugin") {
+		t.Errorf("expected a directory without skills to be rejected, got %v", err)
+	}
+
+	writePlugin(t, tmp, map[string]string{"skills/a/SKILL.md": "Body.\n"})
+	if _, err := Install(pluginsFile, tmp, Options{Scope: "project"}); err == nil {
+		t.Error("expected the project scope to need a project path")
+	}
+
+	// A missing plugins file is created.
+	c, err := Install(pluginsFile, tmp, Options{Scope: "project", ProjectPath: "/work/app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Before != nil || !strings.Contains(string(c.After), `"projectPath": "/work/app"`) || !strings.Contains(string(c.After), `"version": "unknown"`) {
+		t.Errorf("unexpected new file:\n%s", c.After)
+	}
+}
+
+func TestUninstallScope(t *testing.T) {
+	pluginsFile := filepath.Join(t.TempDir(), "installed_plugins.json")
+	both := `{
+  "version": 2,
+  "plugins": {
+    "shipit@team": [
+      {"scope": "user", "installPath": "/plugins/shipit", "version": "0.3.0"},
+      {"scope": "project", "projectPath": "/work/app", "installPath": "/plugins/shipit", "version": "0.3.0"}
+    ]
+  }
+}
+`
+	if err := os.WriteFile(pluginsFile, []byte(both), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Uninstall(pluginsFile, "shipit", "", ""); err == nil || !strings.Contains(err.Error(), "more than one scope") {
+		t.Errorf("expected an error for a plugin installed in two scopes, got %v", err)
+	}
+	if _, err := Uninstall(pluginsFile, "shipit", "project", "/work/other"); err == nil {
+		t.Error("expected an error for another project")
+	}
+
+	c, err := Uninstall(pluginsFile, "shipit", "project", "/work/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := decode(t, c.After)["plugins"].(map[string]any)["shipit@team"].([]any)
+	if len(entries) != 1 || entries[0].(map[string]any)["scope"] != "user" {
+		t.Errorf("expected only the user entry kept, got %v", entries)
+	}
+}
@@ -0,0 +1,275 @@
+// Package journal records every change skillex makes on disk so it can be
+// undone and redone, across restarts. Each entry groups the file operations
+// of one user action, such as applying a profile.
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// FileName is the journal's name inside the skillex directory.
+const FileName = "journal.json"
+
+// maxEntries bounds the journal; the oldest entries are dropped first.
+const maxEntries = 500
+
+// ErrNothingToUndo and ErrNothingToRedo are returned when the journal has
+// no entry in that direction.
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// Kind is the type of a file operation.
+type Kind string
+
+const (
+	// KindRename moves a file from From to To.
+	KindRename Kind = "rename"
+	// KindWrite replaces the content of Path, from Before to After. A nil
+	// Before or After means the file did not exist.
+	KindWrite Kind = "write"
+)
+
+// Action is one reversible file operation.
+type Action struct {
+	Kind   Kind    `json:"kind"`
+	From   string  `json:"from,omitempty"`
+	To     string  `json:"to,omitempty"`
+	Path   string  `json:"path,omitempty"`
+	Before *string `json:"before,omitempty"`
+	After  *string `json:"after,omitempty"`
+}
+
+// Rename returns an action for a file moved from one path to another.
+func Rename(from, to string) Action {
+	return Action{Kind: KindRename, From: from, To: to}
+}
+
+// Write returns an action for a file whose content changed. Pass nil data for
+// a file that did not exist before or does not exist after.
+func Write(path string, before, after []byte) Action {
+	return Action{Kind: KindWrite, Path: path, Before: contentPtr(before), After: contentPtr(after)}
+}
+
+func contentPtr(data []byte) *string {
+	if data == nil {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
+
+// Entry is one recorded user action.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Summary string    `json:"summary"`
+	Actions []Action  `json:"actions"`
+}
+
+// Journal is the undo history. Entries before Applied are in effect; the
+// rest have been undone and can be redone until a new entry is recorded.
+type Journal struct {
+	Entries []Entry `json:"entries"`
+	Applied int     `json:"applied"`
+
+	path string
+}
+
+// Open loads the journal at path. A missing file yields an empty journal.
+func Open(path string) (*Journal, error) {
+	j := &Journal{path: path}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// load replaces j's entries with those on disk.
+func (j *Journal) load() error {
+	j.Entries, j.Applied = nil, 0
+	data, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading journal: %w", err)
+	}
+	if err := json.Unmarshal(data, j); err != nil {
+		return fmt.Errorf("parsing journal: %w", err)
+	}
+	if j.Applied < 0 || j.Applied > len(j.Entries) {
+		j.Applied = len(j.Entries)
+	}
+	return nil
+}
+
+// lock takes an exclusive lock on the journal and re-reads it, so a TUI and
+// the CLI running at the same time do not overwrite each other's entries.
+// The lock is held until the returned function is called.
+func (j *Journal) lock() (func(), error) {
+	unlock, err := state.Lock(j.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := j.load(); err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlock, nil
+}
+
+// Record appends an entry for actions that have already been performed and
+// saves the journal. Undone entries are discarded, as in any editor.
+// Recording no actions is a no-op.
+func (j *Journal) Record(summary string, actions ...Action) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	unlock, err := j.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	j.Entries = append(j.Entries[:j.Applied], Entry{Time: time.Now().UTC(), Summary: summary, Actions: actions})
+	if len(j.Entries) > maxEntries {
+		j.Entries = j.Entries[len(j.Entries)-maxEntries:]
+	}
+	j.Applied = len(j.Entries)
+	return j.save()
+}
+
+// Undo reverts the most recent applied entry and returns it. If one of its
+// actions cannot be reverted, those already reverted are re-applied and the
+// journal is unchanged; an error re-applying them is returned as well.
+func (j *Journal) Undo() (Entry, error) {
+	unlock, err := j.lock()
+	if err != nil {
+		return Entry{}, err
+	}
+	defer unlock()
+	if j.Applied == 0 {
+		return Entry{}, ErrNothingToUndo
+	}
+	e := j.Entries[j.Applied-1]
+	for i := len(e.Actions) - 1; i >= 0; i-- {
+		if err := e.Actions[i].revert(); err != nil {
+			var rollback []error
+			for k := i + 1; k < len(e.Actions); k++ {
+				rollback = append(rollback, e.Actions[k].apply())
+			}
+			return e, withRollback(fmt.Errorf("undoing %q: %w", e.Summary, err), rollback)
+		}
+	}
+	j.Applied--
+	return e, j.save()
+}
+
+// Redo re-applies the most recently undone entry and returns it. If one of
+// its actions fails, those already applied are reverted, as in Undo.
+func (j *Journal) Redo() (Entry, error) {
+	unlock, err := j.lock()
+	if err != nil {
+		return Entry{}, err
+	}
+	defer unlock()
+	if j.Applied == len(j.Entries) {
+		return Entry{}, ErrNothingToRedo
+	}
+	e := j.Entries[j.Applied]
+	for i, a := range e.Actions {
+		if err := a.apply(); err != nil {
+			var rollback []error
+			for k := i - 1; k >= 0; k-- {
+				rollback = append(rollback, e.Actions[k].revert())
+			}
+			return e, withRollback(fmt.Errorf("redoing %q: %w", e.Summary, err), rollback)
+		}
+	}
+	j.Applied++
+	return e, j.save()
+}
+
+// withRollback adds the errors of a failed rollback to err, since the disk is
+// then left partly changed and the user needs to know which files.
+func withRollback(err error, rollback []error) error {
+	if rerr := errors.Join(rollback...); rerr != nil {
+		return fmt.Errorf("%w; rolling back also failed: %w", err, rerr)
+	}
+	return err
+}
+
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding journal: %w", err)
+	}
+	if err := state.WriteFileAtomic(j.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("writing journal: %w", err)
+	}
+	return nil
+}
+
+// apply performs the action forwards, refusing if the disk no longer looks
+// the way it did before the action was first made.
+func (a Action) apply() error {
+	switch a.Kind {
+	case KindRename:
+		return move(a.From, a.To)
+	case KindWrite:
+		return replace(a.Path, a.Before, a.After)
+	}
+	return fmt.Errorf("unknown action %q", a.Kind)
+}
+
+// revert performs the action backwards.
+func (a Action) revert() error {
+	switch a.Kind {
+	case KindRename:
+		return move(a.To, a.From)
+	case KindWrite:
+		return replace(a.Path, a.After, a.Before)
+	}
+	return fmt.Errorf("unknown action %q", a.Kind)
+}
+
+// move renames from to to, refusing to overwrite an existing file.
+func move(from, to string) error {
+	err := state.MoveNoReplace(from, to)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("%s no longer exists", from)
+	case errors.Is(err, fs.ErrExist):
+		return fmt.Errorf("%s already exists", to)
+	}
+	return err
+}
+
+// replace sets path's content from want to next, refusing if the current
+// content is not want.
+func replace(path string, want, next *string) error {
+	current, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		if want != nil {
+			return fmt.Errorf("%s no longer exists", path)
+		}
+	case err != nil:
+		return err
+	case want == nil || !bytes.Equal(current, []byte(*want)):
+		return fmt.Errorf("%s was changed outside skillex", path)
+	}
+
+	if next == nil {
+		return os.Remove(path)
+	}
+	return state.WriteFileAtomic(path, []byte(*next))
+}
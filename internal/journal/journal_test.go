@@ -0,0 +1,199 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestUndoRedoRename(t *testing.T) {
+	dir := t.TempDir()
+	from, to := filepath.Join(dir, "SKILL.md"), filepath.Join(dir, "SKILL.md.disabled")
+	writeFile(t, from, "x")
+	if err := os.Rename(from, to); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, FileName)
+	j, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Record("disable p:x", Rename(from, to)); err != nil {
+		t.Fatalf("Record() error: %v", err)
+	}
+
+	// Undo and redo must work across a reload, as after a restart.
+	j, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := j.Undo()
+	if err != nil {
+		t.Fatalf("Undo() error: %v", err)
+	}
+	if e.Summary != "disable p:x" || !exists(from) || exists(to) {
+		t.Errorf("expected %s restored, got entry %+v", from, e)
+	}
+	if _, err := j.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+
+	j, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Redo(); err != nil {
+		t.Fatalf("Redo() error: %v", err)
+	}
+	if exists(from) || !exists(to) {
+		t.Error("expected rename re-applied")
+	}
+	if _, err := j.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+func TestRecordDropsRedoTail(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	writeFile(t, b, "x")
+
+	j, _ := Open(filepath.Join(dir, FileName))
+	if err := j.Record("first", Rename(a, b)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Record("second", Write(filepath.Join(dir, "c"), nil, []byte("new"))); err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Entries) != 1 || j.Entries[0].Summary != "second" || j.Applied != 1 {
+		t.Errorf("expected only the new entry, got %+v (applied %d)", j.Entries, j.Applied)
+	}
+}
+
+func TestRecordKeepsConcurrentEntries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+
+	// Two processes, such as the TUI and the CLI, open the journal before
+	// either records anything.
+	tui, _ := Open(path)
+	cli, _ := Open(path)
+	writeFile(t, filepath.Join(dir, "a"), "a")
+	writeFile(t, filepath.Join(dir, "b"), "b")
+	if err := tui.Record("from the TUI", Write(filepath.Join(dir, "a"), nil, []byte("a"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Record("from the CLI", Write(filepath.Join(dir, "b"), nil, []byte("b"))); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Entries) != 2 || j.Applied != 2 {
+		t.Fatalf("expected both entries kept, got %+v (applied %d)", j.Entries, j.Applied)
+	}
+	if e, err := tui.Undo(); err != nil || e.Summary != "from the CLI" {
+		t.Errorf("expected undo to revert the latest entry on disk, got %+v, %v", e, err)
+	}
+}
+
+func TestUndoRenameRefusesToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	from, to := filepath.Join(dir, "SKILL.md"), filepath.Join(dir, "SKILL.md.disabled")
+	writeFile(t, to, "x")
+
+	j, _ := Open(filepath.Join(dir, FileName))
+	if err := j.Record("disable p:x", Rename(from, to)); err != nil {
+		t.Fatal(err)
+	}
+	// A plugin update brought SKILL.md back.
+	writeFile(t, from, "updated")
+	if _, err := j.Undo(); err == nil {
+		t.Fatal("expected undo to refuse to overwrite SKILL.md")
+	}
+	if data, _ := os.ReadFile(from); string(data) != "updated" || !exists(to) {
+		t.Error("expected both files left untouched")
+	}
+}
+
+func TestUndoWriteRefusesOutsideChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	writeFile(t, path, "after")
+
+	j, _ := Open(filepath.Join(dir, FileName))
+	if err := j.Record("save profile", Write(path, []byte("before"), []byte("after"))); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "edited by hand")
+
+	if _, err := j.Undo(); err == nil {
+		t.Fatal("expected Undo() to refuse a file changed outside skillex")
+	}
+	if j.Applied != 1 {
+		t.Errorf("expected entry to stay applied, got applied=%d", j.Applied)
+	}
+
+	writeFile(t, path, "after")
+	if _, err := j.Undo(); err != nil {
+		t.Fatalf("Undo() error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "before" {
+		t.Errorf("expected content restored, got %q", data)
+	}
+}
+
+func TestUndoRollsBackPartialEntry(t *testing.T) {
+	dir := t.TempDir()
+	a1, a2 := filepath.Join(dir, "a1"), filepath.Join(dir, "a2")
+	b1, b2 := filepath.Join(dir, "b1"), filepath.Join(dir, "b2")
+	writeFile(t, a2, "a")
+	writeFile(t, b2, "b")
+	// Undo reverts b first; a1 reappearing then blocks reverting a.
+	writeFile(t, a1, "blocker")
+
+	j, _ := Open(filepath.Join(dir, FileName))
+	if err := j.Record("two", Rename(a1, a2), Rename(b1, b2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Undo(); err == nil {
+		t.Fatal("expected Undo() to fail")
+	}
+	if !exists(b2) || exists(b1) {
+		t.Error("expected the already reverted rename to be re-applied")
+	}
+	if j.Applied != 1 {
+		t.Errorf("expected entry to stay applied, got applied=%d", j.Applied)
+	}
+}
+
+func TestWithRollback(t *testing.T) {
+	failed := errors.New("undoing")
+	if err := withRollback(failed, []error{nil, nil}); err != failed {
+		t.Errorf("expected the error unchanged after a clean rollback, got %v", err)
+	}
+	lost := errors.New("b2 already exists")
+	err := withRollback(failed, []error{nil, lost})
+	if !errors.Is(err, failed) || !errors.Is(err, lost) {
+		t.Errorf("expected both the failure and the rollback error, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Package lint checks skills for structural problems that Claude Code will not
+// report on its own, such as SKILL.md pointing at files that are not bundled.
+package lint
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// Severity ranks how serious a finding is.
+type Severity int
+
+const (
+	// Warning marks something that works but is likely unintended.
+	Warning Severity = iota
+	// Error marks something that is broken for whoever invokes the skill.
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Finding is a single lint result for a skill.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	// Path is the bundled file the finding is about, relative to the skill
+	// directory. Empty for findings about SKILL.md as a whole.
+	Path    string
+	Message string
+}
+
+// Rule identifiers reported in Finding.Rule.
+const (
+	RuleMissingFile      = "missing-file"
+	RuleUnreferencedFile = "unreferenced-file"
+	RuleNestedSkill      = "nested-skill"
+)
+
+// conventionalDirs are the folder names skills commonly bundle resources in.
+// Inline code spans starting with one of these are treated as file references
+// even when the folder does not exist.
+var conventionalDirs = []string{"scripts", "references", "reference", "templates", "examples", "assets"}
+
+var (
+	// linkTarget matches the target of a markdown link or image: [text](target).
+	linkTarget = regexp.MustCompile(`\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	// codeSpan matches single-word inline code such as `scripts/run.sh`.
+	codeSpan = regexp.MustCompile("`([^`\\s]+)`")
+)
+
+// Check runs all lint rules against a skill and returns findings sorted by
+// severity (errors first), then path.
+func Check(skill discovery.Skill) []Finding {
+	var findings []Finding
+	findings = append(findings, checkLayout(skill)...)
+	findings = append(findings, checkReferences(skill)...)
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity > findings[j].Severity
+		}
+		return findings[i].Path < findings[j].Path
+	})
+	return findings
+}
+
+// checkLayout flags skills in category folders, which skillex lists but
+// Claude Code does not load: it only looks one folder deep.
+func checkLayout(skill discovery.Skill) []Finding {
+	if skill.Category == "" {
+		return nil
+	}
+	return []Finding{{
+		Rule:     RuleNestedSkill,
+		Severity: Warning,
+		Message:  fmt.Sprintf("the skill is nested in %s/; Claude Code only loads skills directly inside the skills folder", skill.Category),
+	}}
+}
+
+// checkReferences flags files referenced from SKILL.md that are not bundled and
+// bundled files that SKILL.md never mentions.
+func checkReferences(skill discovery.Skill) []Finding {
+	bundled := make(map[string]bool, len(skill.Files))
+	topLevel := make(map[string]bool)
+	for _, f := range skill.Files {
+		bundled[f.Path] = true
+		topLevel[strings.SplitN(f.Path, "/", 2)[0]] = true
+	}
+	for _, d := range conventionalDirs {
+		topLevel[d] = true
+	}
+
+	var findings []Finding
+	for _, ref := range References(skill.Content, topLevel) {
+		// A truncated inventory cannot prove a file missing.
+		if bundled[ref] || isBundledDir(ref, skill.Files) || skill.FilesTruncated {
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:     RuleMissingFile,
+			Severity: Error,
+			Path:     ref,
+			Message:  fmt.Sprintf("SKILL.md references %s, which is not bundled with the skill", ref),
+		})
+	}
+
+	for _, f := range skill.Files {
+		if mentioned(skill.Content, f.Path) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:     RuleUnreferencedFile,
+			Severity: Warning,
+			Path:     f.Path,
+			Message:  fmt.Sprintf("%s is bundled but never mentioned in SKILL.md", f.Path),
+		})
+	}
+	return findings
+}
+
+// References extracts the relative file paths SKILL.md points at: markdown
+// link targets, plus inline code spans whose first path segment is one of
+// topLevel. URLs, anchors and absolute paths are ignored. The result is
+// deduplicated and cleaned (no leading "./").
+func References(content string, topLevel map[string]bool) []string {
+	seen := make(map[string]bool)
+	var refs []string
+	add := func(ref string) {
+		if ref == "" || seen[ref] {
+			return
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+
+	for _, m := range linkTarget.FindAllStringSubmatch(content, -1) {
+		add(cleanRef(m[1]))
+	}
+	for _, m := range codeSpan.FindAllStringSubmatch(content, -1) {
+		ref := cleanRef(m[1])
+		if !strings.Contains(ref, "/") {
+			continue
+		}
+		if topLevel[strings.SplitN(ref, "/", 2)[0]] {
+			add(ref)
+		}
+	}
+	return refs
+}
+
+// cleanRef normalizes a reference target, returning "" for anything that is
+// not a relative path inside the skill directory.
+func cleanRef(ref string) string {
+	if strings.Contains(ref, "://") || strings.HasPrefix(ref, "#") ||
+		strings.HasPrefix(ref, "mailto:") || strings.HasPrefix(ref, "/") ||
+		strings.HasPrefix(ref, "~") || strings.HasPrefix(ref, "$") {
+		return ""
+	}
+	if idx := strings.IndexAny(ref, "#?"); idx != -1 {
+		ref = ref[:idx]
+	}
+	ref = strings.TrimRight(ref, ".,;:")
+	if ref == "" {
+		return ""
+	}
+	ref = path.Clean(ref)
+	if ref == "." || ref == "SKILL.md" || strings.HasPrefix(ref, "../") || strings.ContainsAny(ref, "*{}<>") {
+		return ""
+	}
+	return ref
+}
+
+// isBundledDir reports whether ref names a directory that contains bundled files.
+func isBundledDir(ref string, files []discovery.SkillFile) bool {
+	prefix := strings.TrimSuffix(ref, "/") + "/"
+	for _, f := range files {
+		if strings.HasPrefix(f.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentioned reports whether content refers to a bundled file by its relative
+// path, its base name, or one of its parent directories.
+func mentioned(content, rel string) bool {
+	if strings.Contains(content, rel) || strings.Contains(content, path.Base(rel)) {
+		return true
+	}
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if strings.Contains(content, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
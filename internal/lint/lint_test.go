@@ -0,0 +1,82 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+func TestCheckReferences(t *testing.T) {
+	skill := discovery.Skill{
+		Name: "auditing",
+		Content: "Read [the reference](reference.md) first.\n\n" +
+			"Then run `scripts/check.sh` and fill in [the template](./templates/report.md#top).\n\n" +
+			"See https://example.com/docs and `src/main.go` in your project.",
+		Files: []discovery.SkillFile{
+			{Path: "reference.md"},
+			{Path: "scripts/check.sh", Type: discovery.FileScript},
+			{Path: "scripts/helpers/lib.sh", Type: discovery.FileScript},
+			{Path: "notes.txt"},
+		},
+	}
+
+	findings := Check(skill)
+
+	got := make(map[string]string)
+	for _, f := range findings {
+		got[f.Path] = f.Rule
+	}
+
+	if got["templates/report.md"] != RuleMissingFile {
+		t.Errorf("expected missing-file for templates/report.md, got %+v", findings)
+	}
+	if got["notes.txt"] != RuleUnreferencedFile {
+		t.Errorf("expected unreferenced-file for notes.txt, got %+v", findings)
+	}
+	for _, path := range []string{"reference.md", "scripts/check.sh", "scripts/helpers/lib.sh", "src/main.go"} {
+		if rule, ok := got[path]; ok {
+			t.Errorf("unexpected %s finding for %s", rule, path)
+		}
+	}
+	if len(findings) != 2 {
+		t.Errorf("expected 2 findings, got %d: %+v", len(findings), findings)
+	}
+	if findings[0].Severity != Error {
+		t.Error("expected errors to sort before warnings")
+	}
+
+	// A truncated inventory cannot tell a missing file from an unlisted one.
+	skill.FilesTruncated = true
+	for _, f := range Check(skill) {
+		if f.Rule == RuleMissingFile {
+			t.Errorf("unexpected missing-file with a truncated inventory: %+v", f)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	content := "[a](docs/a.md) [b](<docs/b.md>) [c](#anchor) [d](mailto:x@y.z) " +
+		"[e](../outside.md) [f](/etc/passwd) `scripts/x.py` `foo/bar` `x.py`"
+
+	refs := References(content, map[string]bool{"scripts": true})
+
+	want := []string{"docs/a.md", "docs/b.md", "scripts/x.py"}
+	if len(refs) != len(want) {
+		t.Fatalf("expected %v, got %v", want, refs)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("ref %d: expected %q, got %q", i, want[i], refs[i])
+		}
+	}
+}
+
+func TestCheckLayout(t *testing.T) {
+	if findings := Check(discovery.Skill{Name: "flat"}); len(findings) != 0 {
+		t.Errorf("flat skill: unexpected findings %+v", findings)
+	}
+	findings := Check(discovery.Skill{Name: "deploy", Category: "ops/release"})
+	if len(findings) != 1 || findings[0].Rule != RuleNestedSkill || findings[0].Severity != Warning {
+		t.Errorf("nested skill: expected one nested-skill warning, got %+v", findings)
+	}
+}
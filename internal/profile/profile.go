@@ -0,0 +1,141 @@
+// Package profile computes and applies changes between the skills' current
+// enabled state and a desired one, such as a saved profile.
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/smauermann/skillex/internal/backend"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/journal"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// ErrNotJournaled is returned, wrapped, when changes were applied but could
+// not be recorded in the journal, so undo will not cover them.
+var ErrNotJournaled = errors.New("changes applied but not journaled")
+
+// Capture snapshots the enabled state of every skill as a profile.
+func Capture(skills []discovery.Skill) state.Profile {
+	p := state.Profile{Skills: make(map[string]bool, len(skills)), SavedAt: time.Now().UTC()}
+	for _, s := range skills {
+		p.Skills[s.ID()] = s.Enabled
+	}
+	return p
+}
+
+// Change is one skill whose enabled state has to flip.
+type Change struct {
+	// Index is the skill's position in the slice passed to Diff.
+	Index  int
+	ID     string
+	Enable bool
+}
+
+func (c Change) String() string {
+	if c.Enable {
+		return "enable  " + c.ID
+	}
+	return "disable " + c.ID
+}
+
+// Plan is the result of comparing skills against a desired state.
+type Plan struct {
+	Changes []Change
+	// Missing lists desired skill IDs that are not installed, sorted.
+	Missing []string
+}
+
+// Diff compares the enabled state of skills with desired, a map of skill ID
+// to enabled. Skills absent from desired are left alone.
+func Diff(skills []discovery.Skill, desired map[string]bool) Plan {
+	var plan Plan
+	seen := make(map[string]bool, len(skills))
+	for i, s := range skills {
+		id := s.ID()
+		seen[id] = true
+		want, ok := desired[id]
+		if !ok || want == s.Enabled {
+			continue
+		}
+		plan.Changes = append(plan.Changes, Change{Index: i, ID: id, Enable: want})
+	}
+	for id := range desired {
+		if !seen[id] {
+			plan.Missing = append(plan.Missing, id)
+		}
+	}
+	sort.Strings(plan.Missing)
+	return plan
+}
+
+// Apply toggles every skill in changes through b, updating skills in place,
+// and returns the file operations it made for the journal. It is all or
+// nothing: if one toggle fails, the ones already made are reverted and the
+// error is returned.
+func Apply(b backend.Backend, skills []discovery.Skill, changes []Change) ([]journal.Action, error) {
+	var actions []journal.Action
+	var toggled []int
+	for _, c := range changes {
+		s := &skills[c.Index]
+		if s.Enabled == c.Enable {
+			continue
+		}
+		done, err := b.Toggle(s)
+		if err != nil {
+			rollback(b, skills, toggled)
+			return nil, fmt.Errorf("%s: %w", c.ID, err)
+		}
+		toggled = append(toggled, c.Index)
+		actions = append(actions, done...)
+	}
+	return actions, nil
+}
+
+// rollback toggles the skills at the given indexes back, in reverse order.
+// Errors are ignored: the original failure is what the caller needs to see.
+func rollback(b backend.Backend, skills []discovery.Skill, toggled []int) {
+	for i := len(toggled) - 1; i >= 0; i-- {
+		_, _ = b.Toggle(&skills[toggled[i]])
+	}
+}
+
+// ApplyRecorded applies changes like Apply and records them in the journal
+// at journalFile under summary, so they can be undone later. The changes
+// stay applied if only the journal cannot be written; the error returned
+// then wraps ErrNotJournaled.
+func ApplyRecorded(b backend.Backend, skills []discovery.Skill, changes []Change, journalFile, summary string) error {
+	actions, err := Apply(b, skills, changes)
+	if err != nil {
+		return err
+	}
+	return Record(journalFile, summary, actions)
+}
+
+// Record appends actions to the journal at journalFile under summary. Its
+// errors wrap ErrNotJournaled.
+func Record(journalFile, summary string, actions []journal.Action) error {
+	j, err := journal.Open(journalFile)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotJournaled, err)
+	}
+	if err := j.Record(summary, actions...); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotJournaled, err)
+	}
+	return nil
+}
+
+// Summary counts how many changes enable and disable skills.
+func Summary(changes []Change) (enabled, disabled int) {
+	for _, c := range changes {
+		if c.Enable {
+			enabled++
+		} else {
+			disabled++
+		}
+	}
+	return
+}
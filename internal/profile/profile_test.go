@@ -0,0 +1,123 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/smauermann/skillex/internal/backend"
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// makeSkills creates one skill directory per name under a temp dir. Names in
+// disabled start out as SKILL.md.disabled.
+func makeSkills(t *testing.T, names []string, disabled map[string]bool) []discovery.Skill {
+	t.Helper()
+	root := t.TempDir()
+	var skills []discovery.Skill
+	for _, name := range names {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, "SKILL.md")
+		if disabled[name] {
+			file += ".disabled"
+		}
+		if err := os.WriteFile(file, []byte("---\nname: "+name+"\n---\nBody.\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		skills = append(skills, discovery.Skill{Name: name, Plugin: "p", FilePath: file, Enabled: !disabled[name]})
+	}
+	return skills
+}
+
+func TestDiffAndApply(t *testing.T) {
+	skills := makeSkills(t, []string{"a", "b", "c"}, map[string]bool{"b": true})
+
+	desired := map[string]bool{"p:a": false, "p:b": true, "p:c": true, "p:gone": true}
+	plan := Diff(skills, desired)
+
+	if len(plan.Changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", plan.Changes)
+	}
+	if plan.Changes[0].ID != "p:a" || plan.Changes[0].Enable {
+		t.Errorf("expected first change to disable p:a, got %+v", plan.Changes[0])
+	}
+	if plan.Changes[1].ID != "p:b" || !plan.Changes[1].Enable {
+		t.Errorf("expected second change to enable p:b, got %+v", plan.Changes[1])
+	}
+	if len(plan.Missing) != 1 || plan.Missing[0] != "p:gone" {
+		t.Errorf("expected p:gone missing, got %v", plan.Missing)
+	}
+
+	actions, err := Apply(backend.Rename{}, skills, plan.Changes)
+	if err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	if len(actions) != 2 || actions[0].To != skills[0].FilePath {
+		t.Errorf("expected 2 rename actions ending at the new paths, got %+v", actions)
+	}
+	if skills[0].Enabled || !skills[1].Enabled || !skills[2].Enabled {
+		t.Errorf("unexpected state after apply: %+v", skills)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(skills[0].FilePath), "SKILL.md.disabled")); err != nil {
+		t.Error("expected a/SKILL.md.disabled on disk")
+	}
+
+	if again := Diff(skills, desired); len(again.Changes) != 0 {
+		t.Errorf("expected no changes after apply, got %+v", again.Changes)
+	}
+}
+
+func TestApplyRollsBackOnFailure(t *testing.T) {
+	skills := makeSkills(t, []string{"a", "b"}, nil)
+
+	// Remove b's file so toggling it fails after a was already disabled.
+	if err := os.Remove(skills[1].FilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := Diff(skills, map[string]bool{"p:a": false, "p:b": false})
+	if _, err := Apply(backend.Rename{}, skills, plan.Changes); err == nil {
+		t.Fatal("expected Apply() to fail")
+	}
+	if !skills[0].Enabled {
+		t.Error("expected a to be re-enabled by rollback")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(skills[0].FilePath), "SKILL.md")); err != nil {
+		t.Error("expected a/SKILL.md restored on disk")
+	}
+}
+
+func TestApplyRollbackSkipsUntouchedSkills(t *testing.T) {
+	skills := makeSkills(t, []string{"a", "b", "c"}, map[string]bool{"a": true})
+	if err := os.Remove(skills[2].FilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	// a is already disabled, so its change is skipped; only b is toggled
+	// before c fails.
+	changes := []Change{
+		{Index: 0, ID: "p:a", Enable: false},
+		{Index: 1, ID: "p:b", Enable: false},
+		{Index: 2, ID: "p:c", Enable: false},
+	}
+	if _, err := Apply(backend.Rename{}, skills, changes); err == nil {
+		t.Fatal("expected Apply() to fail")
+	}
+	if skills[0].Enabled {
+		t.Error("expected a left disabled: rollback must not touch skipped changes")
+	}
+	if !skills[1].Enabled {
+		t.Error("expected b to be re-enabled by rollback")
+	}
+}
+
+func TestCapture(t *testing.T) {
+	skills := makeSkills(t, []string{"a", "b"}, map[string]bool{"b": true})
+	p := Capture(skills)
+	if !p.Skills["p:a"] || p.Skills["p:b"] || len(p.Skills) != 2 {
+		t.Errorf("unexpected captured profile %+v", p.Skills)
+	}
+}
@@ -0,0 +1,94 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the declarative skill selection committed to a
+// repository, relative to the project root.
+const ProjectFileName = ".claude/skillex.yaml"
+
+// Project is the content of a project's skillex.yaml. Entries in Skills are
+// skill IDs ("plugin:skill") or bare skill names matching any plugin;
+// entries in Plugins select every skill of a plugin. Skill entries win over
+// plugin entries, and disable wins over enable at the same level.
+type Project struct {
+	Skills  Selection `yaml:"skills"`
+	Plugins Selection `yaml:"plugins"`
+}
+
+// Selection lists names to enable and to disable.
+type Selection struct {
+	Enable  []string `yaml:"enable"`
+	Disable []string `yaml:"disable"`
+}
+
+// LoadProject reads a project file. It returns nil and no error when the
+// file does not exist.
+func LoadProject(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading project file: %w", err)
+	}
+
+	var p Project
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parsing project file %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Desired resolves the project file against installed skills, returning the
+// desired enabled state per skill ID and the entries that matched nothing,
+// sorted.
+func (p *Project) Desired(skills []discovery.Skill) (desired map[string]bool, unmatched []string) {
+	desired = make(map[string]bool)
+	used := make(map[string]bool)
+
+	// Apply the weaker rules first so stronger ones overwrite them.
+	apply := func(names []string, enable bool, match func(discovery.Skill, string) bool) {
+		for _, name := range names {
+			for _, s := range skills {
+				if match(s, name) {
+					desired[s.ID()] = enable
+					used[name] = true
+				}
+			}
+		}
+	}
+	byPlugin := func(s discovery.Skill, name string) bool { return s.Plugin == name }
+	bySkill := func(s discovery.Skill, name string) bool { return s.ID() == name || s.Name == name }
+
+	apply(p.Plugins.Enable, true, byPlugin)
+	apply(p.Plugins.Disable, false, byPlugin)
+	apply(p.Skills.Enable, true, bySkill)
+	apply(p.Skills.Disable, false, bySkill)
+
+	for _, names := range [][]string{p.Plugins.Enable, p.Plugins.Disable, p.Skills.Enable, p.Skills.Disable} {
+		for _, name := range names {
+			if !used[name] {
+				unmatched = append(unmatched, name)
+				used[name] = true
+			}
+		}
+	}
+	sort.Strings(unmatched)
+	return desired, unmatched
+}
+
+// Plan compares skills with the project file. Entries that match no
+// installed skill are reported in Plan.Missing.
+func (p *Project) Plan(skills []discovery.Skill) Plan {
+	desired, unmatched := p.Desired(skills)
+	plan := Diff(skills, desired)
+	plan.Missing = unmatched
+	return plan
+}
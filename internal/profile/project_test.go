@@ -0,0 +1,67 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+func TestProjectDesired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skillex.yaml")
+	if err := os.WriteFile(path, []byte(`
+plugins:
+  disable: [superpowers]
+skills:
+  enable:
+    - superpowers:brainstorming
+    - notes
+  disable:
+    - not-installed
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := LoadProject(path)
+	if err != nil {
+		t.Fatalf("LoadProject() error: %v", err)
+	}
+
+	skills := []discovery.Skill{
+		{Name: "brainstorming", Plugin: "superpowers", Enabled: false},
+		{Name: "writing-plans", Plugin: "superpowers", Enabled: true},
+		{Name: "notes", Plugin: "local", Enabled: false},
+		{Name: "other", Plugin: "local", Enabled: true},
+	}
+
+	desired, unmatched := project.Desired(skills)
+	want := map[string]bool{
+		"superpowers:brainstorming": true, // skill rule beats plugin rule
+		"superpowers:writing-plans": false,
+		"local:notes":               true,
+	}
+	if len(desired) != len(want) {
+		t.Errorf("expected %v, got %v", want, desired)
+	}
+	for id, on := range want {
+		if got, ok := desired[id]; !ok || got != on {
+			t.Errorf("%s: expected %v, got %v (present=%v)", id, on, got, ok)
+		}
+	}
+	if len(unmatched) != 1 || unmatched[0] != "not-installed" {
+		t.Errorf("expected not-installed unmatched, got %v", unmatched)
+	}
+
+	plan := project.Plan(skills)
+	if len(plan.Changes) != 3 {
+		t.Errorf("expected 3 changes, got %+v", plan.Changes)
+	}
+}
+
+func TestLoadProjectMissing(t *testing.T) {
+	project, err := LoadProject(filepath.Join(t.TempDir(), "skillex.yaml"))
+	if err != nil || project != nil {
+		t.Errorf("expected nil project and no error, got %v, %v", project, err)
+	}
+}
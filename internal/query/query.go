@@ -0,0 +1,248 @@
+// Package query parses and evaluates skill filter queries such as
+//
+//	plugin:superpowers status:disabled activation:passive desc:>300 has:allowed-tools text:"git commit"
+//
+// A query is a list of space-separated terms that must all match. A term is
+// either field:value or a bare word matched against the skill's name and
+// plugin. Prefixing a term with "-" negates it; double quotes group words.
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// Fields lists the supported field names, for help output.
+var Fields = []string{"plugin", "marketplace", "category", "name", "status", "activation", "desc", "words", "has", "tool", "text"}
+
+// Query is a parsed filter. The zero Query matches every skill.
+type Query struct {
+	terms []term
+}
+
+type term struct {
+	negate bool
+	match  func(discovery.Skill) bool
+	// field is empty for bare words.
+	field string
+}
+
+// Parse parses a query string.
+func Parse(s string) (*Query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{}
+	for _, tok := range tokens {
+		t, err := parseTerm(tok)
+		if err != nil {
+			return nil, err
+		}
+		q.terms = append(q.terms, t)
+	}
+	return q, nil
+}
+
+// Match reports whether the skill satisfies every term.
+func (q *Query) Match(s discovery.Skill) bool {
+	for _, t := range q.terms {
+		if t.match(s) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Structured reports whether the query uses any field:value term. Queries
+// made of bare words only can be served by a plain fuzzy match instead.
+func (q *Query) Structured() bool {
+	for _, t := range q.terms {
+		if t.field != "" || t.negate {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize splits s on unquoted whitespace and strips the quotes.
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	inQuote, started := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			started = true
+		case unicode.IsSpace(r) && !inQuote:
+			if started {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				started = false
+			}
+		default:
+			cur.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if started {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+func parseTerm(tok string) (term, error) {
+	var t term
+	if len(tok) > 1 && tok[0] == '-' {
+		t.negate = true
+		tok = tok[1:]
+	}
+
+	field, value, ok := strings.Cut(tok, ":")
+	if !ok || field == "" {
+		word := strings.ToLower(tok)
+		t.match = func(s discovery.Skill) bool {
+			return contains(s.Name, word) || contains(s.Plugin, word)
+		}
+		return t, nil
+	}
+	t.field = strings.ToLower(field)
+	if value == "" {
+		return t, fmt.Errorf("%s: missing value", field)
+	}
+
+	lower := strings.ToLower(value)
+	switch t.field {
+	case "plugin":
+		t.match = func(s discovery.Skill) bool { return strings.EqualFold(s.Plugin, value) }
+	case "marketplace":
+		t.match = func(s discovery.Skill) bool { return strings.EqualFold(s.Marketplace, value) }
+	case "category":
+		// A category also matches the folders nested in it.
+		t.match = func(s discovery.Skill) bool {
+			c := strings.ToLower(s.Category)
+			return c == lower || strings.HasPrefix(c, strings.TrimSuffix(lower, "/")+"/")
+		}
+	case "name":
+		t.match = func(s discovery.Skill) bool { return contains(s.Name, lower) }
+	case "text":
+		t.match = func(s discovery.Skill) bool {
+			return contains(s.Name, lower) || contains(s.Description, lower) || contains(s.Content, lower)
+		}
+	case "status":
+		match, err := statusMatcher(lower)
+		if err != nil {
+			return t, err
+		}
+		t.match = match
+	case "activation":
+		switch lower {
+		case "directive", "passive", "unknown":
+		default:
+			return t, fmt.Errorf("activation: want directive, passive or unknown, got %q", value)
+		}
+		t.match = func(s discovery.Skill) bool { return s.ActivationStyle.String() == lower }
+	case "desc", "words":
+		cmp, err := parseComparison(value)
+		if err != nil {
+			return t, fmt.Errorf("%s: %w", field, err)
+		}
+		if t.field == "desc" {
+			t.match = func(s discovery.Skill) bool { return cmp(len(s.Description)) }
+		} else {
+			t.match = func(s discovery.Skill) bool { return cmp(len(strings.Fields(s.Content))) }
+		}
+	case "has":
+		match, err := hasMatcher(lower)
+		if err != nil {
+			return t, err
+		}
+		t.match = match
+	case "tool":
+		t.match = func(s discovery.Skill) bool {
+			for _, tool := range s.AllowedTools {
+				if contains(tool, lower) {
+					return true
+				}
+			}
+			return false
+		}
+	default:
+		return t, fmt.Errorf("unknown field %q (want one of %s)", field, strings.Join(Fields, ", "))
+	}
+	return t, nil
+}
+
+func statusMatcher(value string) (func(discovery.Skill) bool, error) {
+	switch value {
+	case "enabled":
+		return func(s discovery.Skill) bool { return s.Enabled }, nil
+	case "disabled":
+		return func(s discovery.Skill) bool { return !s.Enabled }, nil
+	case "reverted":
+		return func(s discovery.Skill) bool { return s.Reverted }, nil
+	case "conflict":
+		return func(s discovery.Skill) bool { return s.Conflict }, nil
+	}
+	return nil, fmt.Errorf("status: want enabled, disabled, reverted or conflict, got %q", value)
+}
+
+func hasMatcher(value string) (func(discovery.Skill) bool, error) {
+	switch value {
+	case "allowed-tools", "tools":
+		return func(s discovery.Skill) bool { return len(s.AllowedTools) > 0 }, nil
+	case "description":
+		return func(s discovery.Skill) bool { return strings.TrimSpace(s.Description) != "" }, nil
+	case "files":
+		return func(s discovery.Skill) bool { return len(s.Files) > 0 }, nil
+	case "scripts":
+		return func(s discovery.Skill) bool {
+			for _, f := range s.Files {
+				if f.Type == discovery.FileScript {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+	return nil, fmt.Errorf("has: want allowed-tools, description, files or scripts, got %q", value)
+}
+
+// parseComparison parses ">300", ">=300", "<50", "<=50", "=10" or "10".
+func parseComparison(s string) (func(int) bool, error) {
+	op := "="
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(s, candidate) {
+			op, s = candidate, s[len(candidate):]
+			break
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("want a number such as >300, got %q", s)
+	}
+	switch op {
+	case ">=":
+		return func(v int) bool { return v >= n }, nil
+	case "<=":
+		return func(v int) bool { return v <= n }, nil
+	case ">":
+		return func(v int) bool { return v > n }, nil
+	case "<":
+		return func(v int) bool { return v < n }, nil
+	}
+	return func(v int) bool { return v == n }, nil
+}
+
+func contains(s, lowerSub string) bool {
+	return strings.Contains(strings.ToLower(s), lowerSub)
+}
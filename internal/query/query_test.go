@@ -0,0 +1,94 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+var testSkills = []discovery.Skill{
+	{
+		Name: "brainstorming", Plugin: "superpowers", Marketplace: "claude-plugins-official", Enabled: true,
+		Description:     "Use when exploring ideas before writing code.",
+		ActivationStyle: discovery.ActivationPassive,
+		Content:         "Ask one question at a time before you git commit anything.",
+	},
+	{
+		Name: "deploy", Plugin: "infra", Marketplace: "internal", Category: "ops/release", Enabled: false,
+		Description:     "ALWAYS use this skill to deploy. " + strings.Repeat("x", 300),
+		ActivationStyle: discovery.ActivationDirective,
+		AllowedTools:    []string{"Bash(kubectl:*)"},
+		Files:           []discovery.SkillFile{{Path: "scripts/run.sh", Type: discovery.FileScript}},
+	},
+	{Name: "notes", Plugin: "local", Enabled: true},
+}
+
+func matches(t *testing.T, q string) string {
+	t.Helper()
+	parsed, err := Parse(q)
+	if err != nil {
+		t.Fatalf("Parse(%q) error: %v", q, err)
+	}
+	var names []string
+	for _, s := range testSkills {
+		if parsed.Match(s) {
+			names = append(names, s.Name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "brainstorming,deploy,notes"},
+		{"plugin:superpowers", "brainstorming"},
+		{"marketplace:internal", "deploy"},
+		{"category:ops", "deploy"},
+		{"category:ops/release", "deploy"},
+		{"category:op", ""},
+		{"status:disabled", "deploy"},
+		{"-status:disabled", "brainstorming,notes"},
+		{"activation:passive", "brainstorming"},
+		{"activation:unknown", "notes"},
+		{"desc:>300", "deploy"},
+		{"desc:<=0", "notes"},
+		{"has:allowed-tools", "deploy"},
+		{"has:scripts", "deploy"},
+		{"tool:kubectl", "deploy"},
+		{`text:"git commit"`, "brainstorming"},
+		{"status:enabled brain", "brainstorming"},
+		{"POWERS", "brainstorming"},
+		{"words:>5", "brainstorming"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := matches(t, tt.query); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, q := range []string{"status:maybe", "desc:>lots", "colour:red", `text:"open`, "has:", "activation:eager"} {
+		if _, err := Parse(q); err == nil {
+			t.Errorf("Parse(%q): expected an error", q)
+		}
+	}
+}
+
+func TestStructured(t *testing.T) {
+	for q, want := range map[string]bool{"brain storm": false, "plugin:x": true, "-foo": true, "": false} {
+		parsed, err := Parse(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Structured() != want {
+			t.Errorf("Structured(%q) = %v, want %v", q, !want, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"min": func(a, b int) int { return min(a, b) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Skill inventory</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem auto; max-width: 72rem; padding: 0 1rem; color: #222; }
+h1, h2 { font-weight: 600; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5rem; }
+th, td { text-align: left; padding: .35rem .6rem; border-bottom: 1px solid #ddd; vertical-align: top; }
+td.num, th.num { text-align: right; white-space: nowrap; }
+.muted { color: #777; }
+.bar { background: #eee; border-radius: 4px; height: 12px; width: 100%; max-width: 32rem; }
+.bar div { height: 100%; border-radius: 4px; }
+.healthy { background: #2e9d5b; } .tight { background: #e59a1a; } .exceeded { background: #d33; }
+.disabled td { color: #999; }
+.tag { font-size: .8em; padding: .05rem .4rem; border-radius: 3px; background: #eee; }
+.directive { background: #d7f2df; } .passive { background: #fbecc9; }
+.verbose { color: #d33; }
+code { font-size: .9em; }
+</style>
+</head>
+<body>
+<h1>Skill inventory</h1>
+<p class="muted">Generated {{.Generated}} by skillex. {{len .Plugins}} source(s), {{.Enabled}} of {{.Skills}} skill(s) enabled.</p>
+
+<h2>Description budget</h2>
+<p><strong>{{.Total}} / {{.Limit}} chars ({{.Percent}}%)</strong>: {{.Level.Advice}}.</p>
+<div class="bar"><div class="{{.Level}}" style="width: {{min .Percent 100}}%"></div></div>
+{{if .Disabled}}<p class="muted">{{.Disabled}} disabled skill(s) saving {{.DisabledChars}} chars.</p>{{end}}
+{{if .NotLoaded}}<p class="muted">{{.NotLoaded}} skill(s) nested in category folders are not loaded by Claude Code and not counted.</p>{{end}}
+{{if .Verbose}}<p class="muted">Word counts in <span class="verbose">red</span> exceed {{.WordLimit}}: the whole SKILL.md fills context whenever the skill is used.</p>{{end}}
+
+<h2>Activation styles</h2>
+<table>
+<tr><th>Style</th><th class="num">Skills</th><th class="num">Enabled</th></tr>
+{{range .Activation}}<tr><td><span class="tag {{.Style}}">{{.Style}}</span></td><td class="num">{{.Skills}}</td><td class="num">{{.Enabled}}</td></tr>
+{{end}}</table>
+
+{{range .Plugins}}<h2>{{.Name}}{{if .Version}} <span class="muted">{{.Version}}</span>{{end}}</h2>
+<p class="muted">{{.Enabled}} of {{len .Skills}} skill(s) enabled.</p>
+<table>
+<tr><th>Skill</th><th>Status</th><th>Activation</th><th class="num">Description chars</th><th class="num">Words</th><th>Description</th></tr>
+{{range .Skills}}<tr{{if not .Enabled}} class="disabled"{{end}}><td>{{.Name}}</td><td>{{.Status}}</td><td><span class="tag {{.Activation}}">{{.Activation}}</span></td><td class="num">{{.DescChars}}</td><td class="num{{if .Verbose}} verbose{{end}}">{{.Words}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .Failures}}<h2>Parse failures</h2>
+<p>These skills could not be read; Claude Code cannot load them either.</p>
+<ul>
+{{range .Failures}}<li><strong>{{.Plugin}}</strong> <code>{{.Dir}}</code>: {{.Err}}</li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+// HTML renders inv as a standalone HTML page with inline styles.
+func HTML(w io.Writer, inv Inventory) error {
+	return htmlTemplate.Execute(w, summarize(inv))
+}
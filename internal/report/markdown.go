@@ -0,0 +1,68 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Markdown renders inv as a Markdown document.
+func Markdown(w io.Writer, inv Inventory) error {
+	s := summarize(inv)
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "# Skill inventory\n\nGenerated %s by skillex. %d skill(s) in %d source(s), %d enabled.\n\n",
+		s.Generated, s.Skills, len(s.Plugins), s.Enabled)
+
+	fmt.Fprintf(b, "## Description budget\n\n")
+	fmt.Fprintf(b, "**%d / %d chars (%d%%)**: %s.\n\n", s.Total, s.Limit, s.Percent, s.Level.Advice())
+	if s.Disabled > 0 {
+		fmt.Fprintf(b, "%d disabled skill(s) saving %d chars.\n\n", s.Disabled, s.DisabledChars)
+	}
+	if s.NotLoaded > 0 {
+		fmt.Fprintf(b, "%d skill(s) nested in category folders are not loaded by Claude Code and not counted.\n\n", s.NotLoaded)
+	}
+	if s.Verbose > 0 {
+		fmt.Fprintf(b, "Word counts marked ⚠ exceed %d: the whole SKILL.md fills context whenever the skill is used.\n\n", s.WordLimit)
+	}
+
+	fmt.Fprintf(b, "## Activation styles\n\n| Style | Skills | Enabled |\n|---|---:|---:|\n")
+	for _, a := range s.Activation {
+		fmt.Fprintf(b, "| %s | %d | %d |\n", a.Style, a.Skills, a.Enabled)
+	}
+	fmt.Fprintln(b)
+
+	for _, p := range s.Plugins {
+		fmt.Fprintf(b, "## %s", mdCell(p.Name))
+		if p.Version != "" {
+			fmt.Fprintf(b, " (%s)", mdCell(p.Version))
+		}
+		fmt.Fprintf(b, "\n\n%d of %d skill(s) enabled.\n\n", p.Enabled, len(p.Skills))
+		fmt.Fprintf(b, "| Skill | Status | Activation | Description chars | Words | Description |\n|---|---|---|---:|---:|---|\n")
+		for _, sk := range p.Skills {
+			words := fmt.Sprint(sk.Words)
+			if sk.Verbose {
+				words += " ⚠"
+			}
+			fmt.Fprintf(b, "| %s | %s | %s | %d | %s | %s |\n",
+				mdCell(sk.Name), sk.Status, sk.Activation, sk.DescChars, words, mdCell(sk.Description))
+		}
+		fmt.Fprintln(b)
+	}
+
+	if len(s.Failures) > 0 {
+		fmt.Fprintf(b, "## Parse failures\n\nThese skills could not be read; Claude Code cannot load them either.\n\n")
+		for _, f := range s.Failures {
+			fmt.Fprintf(b, "- **%s** `%s`: %s\n", mdCell(f.Plugin), f.Dir, mdCell(f.Err))
+		}
+		fmt.Fprintln(b)
+	}
+	return b.Flush()
+}
+
+// mdCell escapes text for a Markdown table cell or inline use.
+func mdCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,163 @@
+// Package report renders the skill inventory as a self-contained Markdown or
+// HTML document, for sharing a snapshot of a skill set outside the terminal.
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/smauermann/skillex/internal/budget"
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{"md", "html"}
+
+// Inventory is what a report describes.
+type Inventory struct {
+	GeneratedAt time.Time
+	Skills      []discovery.Skill
+	// Failures are skills that could not be parsed.
+	Failures []discovery.Failure
+}
+
+// Write renders inv to w in format, one of Formats.
+func Write(w io.Writer, format string, inv Inventory) error {
+	switch format {
+	case "md":
+		return Markdown(w, inv)
+	case "html":
+		return HTML(w, inv)
+	}
+	return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, " or "))
+}
+
+// summary is the inventory digested into what both formats show.
+type summary struct {
+	Generated string
+	Skills    int
+
+	// Budget counts enabled skills' descriptions, as the analytics panel does.
+	Total, Limit, Percent int
+	Level                 budget.Level
+	Enabled               int
+	Disabled              int
+	DisabledChars         int
+	// NotLoaded counts skills nested in category folders, left out of the
+	// budget because Claude Code does not load them.
+	NotLoaded int
+	// Verbose counts skills longer than WordLimit words.
+	Verbose   int
+	WordLimit int
+
+	Activation []activationRow
+	Plugins    []pluginSection
+	Failures   []failureRow
+}
+
+type activationRow struct {
+	Style   string
+	Skills  int
+	Enabled int
+}
+
+type pluginSection struct {
+	Name    string
+	Version string
+	Enabled int
+	Skills  []skillRow
+}
+
+type skillRow struct {
+	Name        string
+	Enabled     bool
+	Status      string
+	Activation  string
+	DescChars   int
+	Words       int
+	Verbose     bool
+	Description string
+}
+
+type failureRow struct {
+	Plugin, Dir, Err string
+}
+
+func summarize(inv Inventory) summary {
+	s := summary{
+		Generated: inv.GeneratedAt.Format("2006-01-02 15:04 MST"),
+		Skills:    len(inv.Skills),
+		Total:     budget.Total(inv.Skills),
+		Limit:     budget.ResolveLimit(),
+		WordLimit: budget.ContentWordLimit,
+	}
+	s.Percent = s.Total * 100 / s.Limit
+	s.Level = budget.Assess(s.Total, s.Limit)
+	s.Disabled, s.DisabledChars = budget.Disabled(inv.Skills)
+	s.NotLoaded = budget.NotLoaded(inv.Skills)
+	for _, sk := range inv.Skills {
+		if sk.Enabled {
+			s.Enabled++
+		}
+	}
+
+	styles := []discovery.ActivationStyle{discovery.ActivationDirective, discovery.ActivationPassive, discovery.ActivationNeutral}
+	for _, style := range styles {
+		row := activationRow{Style: style.String()}
+		for _, sk := range inv.Skills {
+			if sk.ActivationStyle == style {
+				row.Skills++
+				if sk.Enabled {
+					row.Enabled++
+				}
+			}
+		}
+		s.Activation = append(s.Activation, row)
+	}
+
+	// Plugins in order of first appearance, as in the TUI's plugin groups.
+	index := make(map[string]int)
+	for _, sk := range inv.Skills {
+		i, ok := index[sk.Plugin]
+		if !ok {
+			i = len(s.Plugins)
+			index[sk.Plugin] = i
+			s.Plugins = append(s.Plugins, pluginSection{Name: sk.Plugin, Version: sk.PluginVersion})
+		}
+		words := len(strings.Fields(sk.Frontmatter + " " + sk.Content))
+		s.Plugins[i].Skills = append(s.Plugins[i].Skills, skillRow{
+			Name:        sk.Name,
+			Enabled:     sk.Enabled,
+			Status:      status(sk),
+			Activation:  sk.ActivationStyle.String(),
+			DescChars:   len(sk.Description),
+			Words:       words,
+			Verbose:     words > budget.ContentWordLimit,
+			Description: strings.Join(strings.Fields(sk.Description), " "),
+		})
+		if sk.Enabled {
+			s.Plugins[i].Enabled++
+		}
+		if words > budget.ContentWordLimit {
+			s.Verbose++
+		}
+	}
+
+	for _, f := range inv.Failures {
+		s.Failures = append(s.Failures, failureRow{Plugin: f.Plugin, Dir: f.Dir, Err: f.Err.Error()})
+	}
+	return s
+}
+
+// status is a skill's state as Claude Code sees it, in words.
+func status(sk discovery.Skill) string {
+	switch {
+	case sk.Category != "":
+		return "not loaded"
+	case sk.Enabled:
+		return "enabled"
+	}
+	return "disabled"
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+var testInventory = Inventory{
+	GeneratedAt: time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC),
+	Skills: []discovery.Skill{
+		{Name: "brainstorm", Plugin: "superpowers", PluginVersion: "4.2.0", Enabled: true,
+			Description: "ALWAYS use | when <ideas> matter.", ActivationStyle: discovery.ActivationDirective},
+		{Name: "review", Plugin: "superpowers", PluginVersion: "4.2.0",
+			Description: "Use when reviewing.", ActivationStyle: discovery.ActivationPassive},
+		{Name: "notes", Plugin: "local", Enabled: true, Content: strings.Repeat("word ", 600)},
+	},
+	Failures: []discovery.Failure{{Plugin: "local", Dir: "/skills/broken", Err: errors.New("parsing frontmatter of SKILL.md: bad yaml")}},
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "md", testInventory); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Generated 2026-10-12 09:00 UTC",
+		"3 skill(s) in 2 source(s), 2 enabled",
+		"**33 / 16000 chars (0%)**: Healthy",
+		"1 disabled skill(s) saving 19 chars",
+		"| directive | 1 | 1 |",
+		"## superpowers (4.2.0)",
+		`| brainstorm | enabled | directive | 33 | 0 | ALWAYS use \| when &lt;ideas&gt; matter. |`,
+		"| notes | enabled | unknown | 0 | 600 ⚠ |",
+		"## Parse failures",
+		"`/skills/broken`: parsing frontmatter",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "html", testInventory); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		`<div class="healthy" style="width: 0%">`,
+		"ALWAYS use | when &lt;ideas&gt; matter.",
+		`<tr class="disabled"><td>review</td>`,
+		`<td class="num verbose">600</td>`,
+		"<code>/skills/broken</code>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<ideas>") {
+		t.Error("expected descriptions to be escaped")
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "pdf", testInventory); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestNestedSkillsNotCounted(t *testing.T) {
+	inv := Inventory{Skills: []discovery.Skill{
+		{Name: "flat", Plugin: "local", Enabled: true, Description: "12345"},
+		{Name: "deploy", Plugin: "local", Enabled: true, Category: "ops", Description: "1234567890"},
+		{Name: "rollback", Plugin: "local", Category: "ops", Description: "123"},
+	}}
+	var buf bytes.Buffer
+	if err := Markdown(&buf, inv); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"3 skill(s) in 1 source(s), 2 enabled.", "**5 / 16000 chars", "2 skill(s) nested in category folders", "| deploy | not loaded |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package search is an in-memory full-text index over skill names,
+// descriptions and bodies, built once after discovery.
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// snippetRadius is how many characters of context a snippet keeps on each
+// side of a hit.
+const snippetRadius = 40
+
+// maxSnippets bounds the snippets returned per skill.
+const maxSnippets = 3
+
+// Index maps words to the skills containing them.
+type Index struct {
+	docs []string
+	// postings maps a lowercased word to the sorted indexes of the skills
+	// containing it.
+	postings map[string][]int
+	// words is every indexed word, sorted, for prefix lookups.
+	words []string
+}
+
+// Result is one matching skill.
+type Result struct {
+	// Index is the skill's position in the slice passed to Build.
+	Index int
+	// Count is how many times the query occurs in the skill's text.
+	Count int
+	// Snippets are excerpts around the first hits, with surrounding
+	// whitespace collapsed.
+	Snippets []string
+}
+
+// Build indexes the name, description and body of every skill.
+func Build(skills []discovery.Skill) *Index {
+	ix := &Index{postings: make(map[string][]int)}
+	for i, s := range skills {
+		doc := s.Name + "\n" + s.Description + "\n" + s.Content
+		ix.docs = append(ix.docs, doc)
+		seen := make(map[string]bool)
+		for _, w := range Tokenize(doc) {
+			if seen[w] {
+				continue
+			}
+			seen[w] = true
+			ix.postings[w] = append(ix.postings[w], i)
+		}
+	}
+	for w := range ix.postings {
+		ix.words = append(ix.words, w)
+	}
+	sort.Strings(ix.words)
+	return ix
+}
+
+// Tokenize splits text into lowercased words of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Search returns the skills containing every word of q, the last one
+// matched as a prefix so results update while typing. Skills where the
+// words occur as the exact phrase rank first, then by hit count.
+func (ix *Index) Search(q string) []Result {
+	words := Tokenize(q)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var candidates []int
+	for n, w := range words {
+		var docs []int
+		if n == len(words)-1 {
+			docs = ix.prefixDocs(w)
+		} else {
+			docs = ix.postings[w]
+		}
+		if n == 0 {
+			candidates = docs
+		} else {
+			candidates = intersect(candidates, docs)
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+	}
+
+	phrase := strings.ToLower(strings.Join(strings.Fields(q), " "))
+	results := make([]Result, 0, len(candidates))
+	phraseHits := make(map[int]bool)
+	for _, i := range candidates {
+		r := Result{Index: i}
+		doc := collapse(ix.docs[i])
+		lower := strings.ToLower(doc)
+		if len(lower) != len(doc) {
+			// Lowercasing changed byte offsets; cut snippets from lower.
+			doc = lower
+		}
+		needle := phrase
+		if !strings.Contains(lower, phrase) {
+			// Words present but not adjacent: count and show the first word.
+			needle = words[0]
+		} else {
+			phraseHits[i] = true
+		}
+		covered := -1
+		for at := 0; ; {
+			k := strings.Index(lower[at:], needle)
+			if k < 0 {
+				break
+			}
+			k += at
+			r.Count++
+			// Skip hits already shown in the previous snippet's context.
+			if len(r.Snippets) < maxSnippets && k > covered {
+				r.Snippets = append(r.Snippets, snippet(doc, k, k+len(needle)))
+				covered = k + len(needle) + snippetRadius
+			}
+			at = k + len(needle)
+		}
+		results = append(results, r)
+	}
+
+	sort.SliceStable(results, func(a, b int) bool {
+		pa, pb := phraseHits[results[a].Index], phraseHits[results[b].Index]
+		if pa != pb {
+			return pa
+		}
+		return results[a].Count > results[b].Count
+	})
+	return results
+}
+
+// prefixDocs returns the skills containing a word starting with prefix.
+func (ix *Index) prefixDocs(prefix string) []int {
+	start := sort.SearchStrings(ix.words, prefix)
+	seen := make(map[int]bool)
+	var docs []int
+	for _, w := range ix.words[start:] {
+		if !strings.HasPrefix(w, prefix) {
+			break
+		}
+		for _, d := range ix.postings[w] {
+			if !seen[d] {
+				seen[d] = true
+				docs = append(docs, d)
+			}
+		}
+	}
+	sort.Ints(docs)
+	return docs
+}
+
+// intersect returns the values in both sorted slices.
+func intersect(a, b []int) []int {
+	var out []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// collapse replaces runs of whitespace with single spaces, so snippets and
+// phrases are not broken by line wrapping in the source.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// snippet cuts doc around [start, end), extending to word boundaries.
+func snippet(doc string, start, end int) string {
+	from, to := start-snippetRadius, end+snippetRadius
+	prefix, suffix := "…", "…"
+	if from <= 0 {
+		from, prefix = 0, ""
+	} else if sp := strings.IndexByte(doc[from:start], ' '); sp >= 0 {
+		from += sp + 1
+	}
+	if to >= len(doc) {
+		to, suffix = len(doc), ""
+	} else if sp := strings.LastIndexByte(doc[end:to], ' '); sp >= 0 {
+		to = end + sp
+	}
+	// Without a space nearby the cut may land inside a UTF-8 sequence.
+	for from > 0 && !utf8.RuneStart(doc[from]) {
+		from--
+	}
+	for to < len(doc) && !utf8.RuneStart(doc[to]) {
+		to++
+	}
+	return prefix + doc[from:to] + suffix
+}
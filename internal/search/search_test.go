@@ -0,0 +1,53 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+var testSkills = []discovery.Skill{
+	{Name: "commits", Description: "Write good commit messages.", Content: "Before you git commit, run the tests.\nThen git\ncommit again."},
+	{Name: "review", Description: "Review pull requests.", Content: "Check that each commit builds. Use git log."},
+	{Name: "deploy", Description: "Ship it.", Content: "kubectl apply"},
+}
+
+func TestSearchPhraseRanksFirst(t *testing.T) {
+	ix := Build(testSkills)
+	results := ix.Search("git commit")
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if results[0].Index != 0 || results[0].Count != 2 {
+		t.Errorf("expected commits first with 2 phrase hits across a line break, got %+v", results[0])
+	}
+	if !strings.Contains(results[0].Snippets[0], "git commit") {
+		t.Errorf("expected snippet around the hit, got %q", results[0].Snippets[0])
+	}
+	if results[1].Index != 1 {
+		t.Errorf("expected review second (words not adjacent), got %+v", results[1])
+	}
+}
+
+func TestSearchPrefixAndMisses(t *testing.T) {
+	ix := Build(testSkills)
+	if results := ix.Search("kube"); len(results) != 1 || results[0].Index != 2 {
+		t.Errorf("expected prefix match on kubectl, got %+v", results)
+	}
+	if results := ix.Search("kube apply"); len(results) != 0 {
+		t.Errorf("expected only the last word to match as a prefix, got %+v", results)
+	}
+	if results := ix.Search("   "); results != nil {
+		t.Errorf("expected no results for an empty query, got %+v", results)
+	}
+}
+
+func TestSnippetTrimsToWords(t *testing.T) {
+	doc := strings.Repeat("alpha ", 20) + "needle" + strings.Repeat(" omega", 20)
+	k := strings.Index(doc, "needle")
+	got := snippet(doc, k, k+len("needle"))
+	if !strings.HasPrefix(got, "…alpha") || !strings.HasSuffix(got, "omega…") {
+		t.Errorf("expected snippet cut at word boundaries, got %q", got)
+	}
+}
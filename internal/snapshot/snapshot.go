@@ -0,0 +1,113 @@
+// Package snapshot caches the text of every skill seen by skillex, so a skill
+// can be compared against its previous version after a plugin update or a
+// local edit. The cache lives next to the state file and is safe to delete.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// FileName is the cache file's name inside the skillex directory.
+const FileName = "snapshots.json"
+
+// Version is a skill's text as seen at one point in time.
+type Version struct {
+	Frontmatter string    `json:"frontmatter,omitempty"`
+	Content     string    `json:"content"`
+	SeenAt      time.Time `json:"seenAt"`
+}
+
+// History holds the latest version of a skill and the one before it.
+type History struct {
+	Current  Version  `json:"current"`
+	Previous *Version `json:"previous,omitempty"`
+}
+
+// Cache maps skill IDs to their history.
+type Cache struct {
+	Skills map[string]History `json:"skills"`
+}
+
+// Load reads the cache at path. A missing file yields an empty Cache.
+func Load(path string) (*Cache, error) {
+	c := &Cache{Skills: make(map[string]History)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot cache: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("parsing snapshot cache: %w", err)
+	}
+	if c.Skills == nil {
+		c.Skills = make(map[string]History)
+	}
+	return c, nil
+}
+
+// Capture records the current text of skills. A skill whose text differs
+// from the cached one keeps the cached version as its previous version. It
+// reports whether anything changed, so unchanged caches need no write.
+// Skills that are no longer installed keep their entry.
+func (c *Cache) Capture(skills []discovery.Skill, now time.Time) bool {
+	changed := false
+	for _, s := range skills {
+		h, ok := c.Skills[s.ID()]
+		if ok && h.Current.Frontmatter == s.Frontmatter && h.Current.Content == s.Content {
+			continue
+		}
+		if ok {
+			prev := h.Current
+			h.Previous = &prev
+		}
+		h.Current = Version{Frontmatter: s.Frontmatter, Content: s.Content, SeenAt: now}
+		c.Skills[s.ID()] = h
+		changed = true
+	}
+	return changed
+}
+
+// Previous returns the version of the skill seen before its current text,
+// if its text ever changed.
+func (c *Cache) Previous(id string) (Version, bool) {
+	h, ok := c.Skills[id]
+	if !ok || h.Previous == nil {
+		return Version{}, false
+	}
+	return *h.Previous, true
+}
+
+// Save writes the cache atomically.
+func (c *Cache) Save(path string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encoding snapshot cache: %w", err)
+	}
+	if err := state.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("writing snapshot cache: %w", err)
+	}
+	return nil
+}
+
+// Update loads the cache at path, captures skills and saves it if anything
+// changed.
+func Update(path string, skills []discovery.Skill) (*Cache, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if c.Capture(skills, time.Now()) {
+		if err := c.Save(path); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
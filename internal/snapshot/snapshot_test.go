@@ -0,0 +1,44 @@
+package snapshot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+func TestCaptureKeepsPreviousVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	skill := discovery.Skill{Name: "s", Plugin: "p", Frontmatter: "name: s", Content: "v1"}
+
+	c, err := Update(path, []discovery.Skill{skill})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Previous("p:s"); ok {
+		t.Fatal("expected no previous version on first sight")
+	}
+
+	// An unchanged run leaves nothing to compare against.
+	if c.Capture([]discovery.Skill{skill}, time.Now()) {
+		t.Error("expected an unchanged skill not to change the cache")
+	}
+
+	skill.Content = "v2"
+	if _, err := Update(path, []discovery.Skill{skill}); err != nil {
+		t.Fatal(err)
+	}
+	// Later runs with the same text keep the previous version available.
+	c, err = Update(path, []discovery.Skill{skill})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, ok := c.Previous("p:s")
+	if !ok || prev.Content != "v1" || prev.Frontmatter != "name: s" {
+		t.Errorf("expected v1 as previous version, got %+v %v", prev, ok)
+	}
+	if c.Skills["p:s"].Current.Content != "v2" {
+		t.Errorf("expected v2 as current version, got %+v", c.Skills["p:s"].Current)
+	}
+}
@@ -0,0 +1,22 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Lock takes an exclusive lock on path, held until the returned function is
+// called, so a TUI and the CLI running at the same time do not interleave
+// their read-modify-write cycles on the same file. The lock is taken on a
+// separate path+".lock" file, since atomic writes replace path itself.
+func Lock(path string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("locking %s: %w", filepath.Base(path), err)
+	}
+	unlock, err = lockFile(path + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("locking %s: %w", filepath.Base(path), err)
+	}
+	return unlock, nil
+}
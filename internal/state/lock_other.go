@@ -0,0 +1,35 @@
+//go:build !unix
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// lockWait bounds how long lockFile waits for another process to let go.
+const lockWait = 10 * time.Second
+
+// lockFile creates the file at path exclusively, waiting while another
+// process holds it, and removes it on unlock. A file left behind by a
+// process that died has to be removed by hand; the error names it.
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is held by another skillex; remove it if none is running", path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,162 @@
+// Package state persists skillex's own data, such as saved profiles, in a
+// single JSON file under the Claude config directory. Claude Code never reads
+// this file.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileName is the state file's name inside the skillex directory.
+const FileName = "state.json"
+
+// State is the content of the skillex state file.
+type State struct {
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+	// Backend names the disable mechanism in use; empty means "rename".
+	Backend string `json:"backend,omitempty"`
+	// Disabled lists the IDs of skills disabled through the state backend,
+	// sorted. It outlives plugin updates that restore SKILL.md files.
+	Disabled []string `json:"disabled,omitempty"`
+}
+
+// Profile is a named snapshot of which skills are enabled.
+type Profile struct {
+	// Skills maps skill IDs ("plugin:skill") to whether the skill is enabled.
+	Skills  map[string]bool `json:"skills"`
+	SavedAt time.Time       `json:"savedAt"`
+}
+
+// Load reads the state file at path. A missing file yields an empty State.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+	return decode(data)
+}
+
+// Save writes the state file atomically: the new content goes to a temporary
+// file in the same directory, which then replaces path. Callers that derive
+// the new state from the file's current content use Update instead.
+func (s *State) Save(path string) error {
+	data, err := s.encode()
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	return nil
+}
+
+// Update loads the state file at path, applies fn and saves the result, all
+// while holding the file's lock, so concurrent skillex processes never
+// overwrite each other's changes. Nothing is written when fn fails. It
+// returns the file's content before and after, for the journal; before is
+// nil when the file did not exist.
+func Update(path string, fn func(*State) error) (before, after []byte, err error) {
+	unlock, err := Lock(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	st := &State{}
+	before, err = os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		before = nil
+	case err != nil:
+		return nil, nil, fmt.Errorf("reading state file: %w", err)
+	default:
+		if st, err = decode(before); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := fn(st); err != nil {
+		return nil, nil, err
+	}
+	after, err = st.encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := WriteFileAtomic(path, after); err != nil {
+		return nil, nil, fmt.Errorf("writing state file: %w", err)
+	}
+	return before, after, nil
+}
+
+func decode(data []byte) (*State, error) {
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parsing state file: %w", err)
+	}
+	return &st, nil
+}
+
+func (s *State) encode() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding state file: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it
+// over path, so readers never observe a partially written file. Missing
+// parent directories are created.
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// MoveNoReplace renames from to to without ever replacing an existing file.
+// It hard-links the new name, which fails if the name is taken, then removes
+// the old one. A crash in between leaves two names for the same file, which
+// discovery flags as a conflict and resolves losslessly. Filesystems without
+// hard links fall back to a checked rename.
+func MoveNoReplace(from, to string) error {
+	err := os.Link(from, to)
+	if err == nil {
+		return os.Remove(from)
+	}
+	if errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	if _, statErr := os.Lstat(to); statErr == nil {
+		return fs.ErrExist
+	}
+	return os.Rename(from, to)
+}
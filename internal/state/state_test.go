@@ -0,0 +1,134 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	st, err := Load(filepath.Join(t.TempDir(), "nope", FileName))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(st.Profiles) != 0 {
+		t.Errorf("expected empty state, got %+v", st)
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skillex", FileName)
+	saved := time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC)
+
+	st := &State{Profiles: map[string]Profile{
+		"minimal": {Skills: map[string]bool{"superpowers:brainstorming": true, "local:notes": false}, SavedAt: saved},
+	}}
+	if err := st.Save(path); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	p := loaded.Profiles["minimal"]
+	if !p.Skills["superpowers:brainstorming"] || p.Skills["local:notes"] || len(p.Skills) != 2 {
+		t.Errorf("unexpected profile skills %+v", p.Skills)
+	}
+	if !p.SavedAt.Equal(saved) {
+		t.Errorf("expected SavedAt %v, got %v", saved, p.SavedAt)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the state file after save, found %d entries", len(entries))
+	}
+}
+
+func TestLoadCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for corrupt state file")
+	}
+}
+
+func TestLockExcludes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skillex", FileName)
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		second, err := Lock(path)
+		if err != nil {
+			t.Error(err)
+			close(acquired)
+			return
+		}
+		acquired <- second
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected the second lock to wait for the first")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	if second, ok := <-acquired; ok {
+		second()
+	}
+}
+
+func TestUpdateKeepsConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skillex", FileName)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			_, _, err := Update(path, func(st *State) error {
+				if st.Profiles == nil {
+					st.Profiles = make(map[string]Profile)
+				}
+				st.Profiles[fmt.Sprint("p", i)] = Profile{}
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	st, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Profiles) != 8 {
+		t.Errorf("got %d profiles, want all 8 updates kept", len(st.Profiles))
+	}
+}
+
+func TestUpdateFailureWritesNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	before, _, err := Update(path, func(st *State) error {
+		st.Backend = "state"
+		return errors.New("boom")
+	})
+	if err == nil || before != nil {
+		t.Fatalf("got %q, %v; want nothing and an error", before, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no state file after a failed update, got %v", err)
+	}
+}
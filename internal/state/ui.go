@@ -0,0 +1,49 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// UIFileName is the TUI preferences file's name inside the skillex
+// directory. View settings live apart from the state file so that changing
+// them never conflicts with undoing a journaled state change.
+const UIFileName = "ui.json"
+
+// UI is the TUI's persisted view settings.
+type UI struct {
+	// Sort names the skill list order, such as "plugin" or "modified".
+	Sort string `json:"sort,omitempty"`
+	// Collapsed lists plugins whose group is collapsed in the plugin order.
+	Collapsed []string `json:"collapsed,omitempty"`
+}
+
+// LoadUI reads the preferences file at path. A missing file yields defaults.
+func LoadUI(path string) (*UI, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &UI{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading preferences: %w", err)
+	}
+
+	var ui UI
+	if err := json.Unmarshal(data, &ui); err != nil {
+		return nil, fmt.Errorf("parsing preferences: %w", err)
+	}
+	return &ui, nil
+}
+
+// Save writes the preferences file atomically.
+func (u *UI) Save(path string) error {
+	data, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding preferences: %w", err)
+	}
+	if err := WriteFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("writing preferences: %w", err)
+	}
+	return nil
+}
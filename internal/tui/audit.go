@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/smauermann/skillex/internal/audit"
+)
+
+// severityColors maps audit severities to the palette used elsewhere in the TUI.
+var severityColors = map[audit.Severity]lipgloss.Color{
+	audit.Low:      lipgloss.Color("243"),
+	audit.Medium:   lipgloss.Color("214"),
+	audit.High:     lipgloss.Color("202"),
+	audit.Critical: lipgloss.Color("196"),
+}
+
+// renderAuditFindings renders security audit findings for the Audit tab,
+// most severe first.
+func renderAuditFindings(findings []audit.Finding, width int) string {
+	if len(findings) == 0 {
+		return lipgloss.NewStyle().Foreground(directiveColor).Render("No risky patterns found.") + "\n\n" +
+			fileMetaStyle.Render("The audit checks allowed-tools grants, bundled scripts and hidden\ncontent offline. It flags patterns to review, not verdicts.")
+	}
+
+	counts := make(map[audit.Severity]int)
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	var summary []string
+	for sev := audit.Critical; sev >= audit.Low; sev-- {
+		if counts[sev] > 0 {
+			summary = append(summary, lipgloss.NewStyle().Foreground(severityColors[sev]).Render(fmt.Sprintf("%d %s", counts[sev], sev)))
+		}
+	}
+
+	lines := []string{sectionStyle.Render(fmt.Sprintf("Findings (%d)", len(findings))) + "  " + strings.Join(summary, fileMetaStyle.Render(" · ")), ""}
+	excerptStyle := fileMetaStyle.Width(max(width, 29))
+	for _, f := range findings {
+		loc := f.Path
+		if f.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", f.Path, f.Line)
+		}
+		sev := lipgloss.NewStyle().Foreground(severityColors[f.Severity]).Bold(true).Width(9).Render(strings.ToUpper(f.Severity.String()))
+		lines = append(lines, sev+normalTitleStyle.Render(f.Message))
+		lines = append(lines, strings.Repeat(" ", 9)+fileMetaStyle.Render(f.Rule+" · "+loc))
+		if f.Excerpt != "" {
+			lines = append(lines, excerptStyle.PaddingLeft(9).Render(f.Excerpt))
+		}
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
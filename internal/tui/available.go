@@ -0,0 +1,63 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// withAvailable sets the skills of uninstalled plugins found in local
+// marketplace clones, browsed with A.
+func (m Model) withAvailable(skills []discovery.Skill) Model {
+	m.available = skills
+	return m
+}
+
+// listSkills returns the skills the list shows: the installed ones, or the
+// available ones while browsing.
+func (m Model) listSkills() []discovery.Skill {
+	if m.browsing {
+		return m.available
+	}
+	return m.skills
+}
+
+// toggleAvailable switches the list between installed and available skills.
+func (m Model) toggleAvailable() (Model, tea.Cmd) {
+	if !m.browsing && len(m.available) == 0 {
+		m.setStatus("No uninstalled plugins with skills in the local marketplace clones")
+		return m, nil
+	}
+	m.browsing = !m.browsing
+	m.list.ResetFilter()
+	m.report = ""
+	m.fileCursor, m.fileOpen = 0, false
+	m.focusViewport = false
+	m, cmd := m.syncItems()
+	m.list.Select(0)
+	return m.updateViewportContent(), cmd
+}
+
+// installedOnly reports whether the list shows available skills, setting a
+// status that the action needs installed ones.
+func (m *Model) installedOnly() bool {
+	if m.browsing {
+		m.setStatus("Install the plugin with Claude Code to manage its skills")
+	}
+	return m.browsing
+}
+
+// budgetSkills returns the skills the budget meter counts for skill: the
+// installed ones, plus, for an available skill, every skill its plugin
+// would bring along when installed.
+func (m Model) budgetSkills(skill discovery.Skill) []discovery.Skill {
+	if !skill.Available {
+		return m.skills
+	}
+	skills := append([]discovery.Skill(nil), m.skills...)
+	for _, s := range m.available {
+		if s.PluginDir == skill.PluginDir {
+			skills = append(skills, s)
+		}
+	}
+	return skills
+}
@@ -0,0 +1,245 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/lint"
+	"github.com/smauermann/skillex/internal/profile"
+)
+
+var markStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+
+// Bulk action picker option values.
+const (
+	bulkEnable  = "enable"
+	bulkDisable = "disable"
+	bulkExport  = "export"
+	bulkLint    = "lint"
+	bulkCopy    = "copy"
+	bulkClear   = "clear"
+)
+
+// markSelected toggles the mark on the skill under the cursor and moves the
+// cursor down, so repeated presses mark a run of skills. On a group header
+// it marks or unmarks the whole group.
+func (m Model) markSelected() Model {
+	switch item := m.list.SelectedItem().(type) {
+	case skillItem:
+		key := item.skill.Dir()
+		if m.marked[key] {
+			delete(m.marked, key)
+		} else {
+			m.marked[key] = true
+		}
+	case groupHeader:
+		var keys []string
+		for _, s := range m.skills {
+			if m.groupKey(s) == item.key {
+				keys = append(keys, s.Dir())
+			}
+		}
+		m.toggleMarks(keys)
+	default:
+		return m
+	}
+	m.list.CursorDown()
+	return m.updateViewportContent()
+}
+
+// markVisible marks every skill the filter shows, or unmarks them all if
+// they are already marked.
+func (m Model) markVisible() Model {
+	var keys []string
+	for _, item := range m.list.VisibleItems() {
+		if si, ok := item.(skillItem); ok {
+			keys = append(keys, si.skill.Dir())
+		}
+	}
+	m.toggleMarks(keys)
+	return m
+}
+
+// toggleMarks marks the skills with the given directories, or unmarks them
+// all if every one is already marked.
+func (m Model) toggleMarks(keys []string) {
+	all := len(keys) > 0
+	for _, key := range keys {
+		if !m.marked[key] {
+			all = false
+			break
+		}
+	}
+	for _, key := range keys {
+		if all {
+			delete(m.marked, key)
+		} else {
+			m.marked[key] = true
+		}
+	}
+}
+
+// markedIndexes returns the indexes in m.skills of marked skills, in order.
+func (m Model) markedIndexes() []int {
+	var idx []int
+	for i, s := range m.skills {
+		if m.marked[s.Dir()] {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// openBulkPicker lists the actions available for the marked skills.
+func (m Model) openBulkPicker() Model {
+	idx := m.markedIndexes()
+	if len(idx) == 0 {
+		m.setStatus("Mark skills with v, or all filtered skills with V, first")
+		return m
+	}
+	toEnable, toDisable := 0, 0
+	for _, i := range idx {
+		if m.skills[i].Enabled {
+			toDisable++
+		} else {
+			toEnable++
+		}
+	}
+
+	m.picker = &picker{
+		kind:  pickBulk,
+		title: fmt.Sprintf("%d marked", len(idx)),
+		options: []pickerOption{
+			{label: "Enable", detail: fmt.Sprintf("%d currently disabled", toEnable), value: bulkEnable},
+			{label: "Disable", detail: fmt.Sprintf("%d currently enabled", toDisable), value: bulkDisable},
+			{label: "Export as JSON", detail: "write a file to the current directory", value: bulkExport},
+			{label: "Lint", detail: "show bundled file problems in the preview", value: bulkLint},
+			{label: "Copy paths", detail: "skill directories, one per line", value: bulkCopy},
+			{label: "Clear marks", value: bulkClear},
+		},
+	}
+	return m
+}
+
+// runBulk performs a bulk action on the marked skills.
+func (m Model) runBulk(action string) Model {
+	idx := m.markedIndexes()
+	switch action {
+	case bulkEnable, bulkDisable:
+		enable := action == bulkEnable
+		var changes []profile.Change
+		for _, i := range idx {
+			if m.skills[i].Enabled != enable {
+				changes = append(changes, profile.Change{Index: i, ID: m.skills[i].ID(), Enable: enable})
+			}
+		}
+		summary := fmt.Sprintf("%s %d marked skill(s)", action, len(changes))
+		err := profile.ApplyRecorded(m.backend, m.skills, changes, m.files.Journal(), summary)
+		switch {
+		case errors.Is(err, profile.ErrNotJournaled):
+			m.setError(fmt.Errorf("%s %d skill(s): %w", action, len(changes), err))
+			return m
+		case err != nil:
+			m.setError(fmt.Errorf("%s failed, no changes made: %w", action, err))
+			return m
+		}
+		m.setStatus("%sd %d skill(s)", strings.ToUpper(action[:1])+action[1:], len(changes))
+
+	case bulkExport:
+		path, err := exportSkills(m.skills, idx)
+		if err != nil {
+			m.setError(err)
+			return m
+		}
+		m.setStatus("Exported %d skill(s) to %s", len(idx), path)
+
+	case bulkLint:
+		m.report = renderLintReport(m.skills, idx)
+		m.reportTitle = "Lint report"
+		m.viewport.SetContent(m.report)
+		m.viewport.GotoTop()
+
+	case bulkCopy:
+		paths := make([]string, len(idx))
+		for n, i := range idx {
+			paths[n] = m.skills[i].Dir()
+		}
+		copyToClipboard(strings.Join(paths, "\n"))
+		m.setStatus("Copied %d path(s)", len(paths))
+
+	case bulkClear:
+		for key := range m.marked {
+			delete(m.marked, key)
+		}
+	}
+	return m
+}
+
+// exportSkills writes the skills at idx as JSON to a timestamped file in the
+// working directory and returns its path.
+func exportSkills(skills []discovery.Skill, idx []int) (string, error) {
+	out := make([]discovery.Record, len(idx))
+	for n, i := range idx {
+		out[n] = skills[i].Record()
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding export: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("exporting: %w", err)
+	}
+	path := filepath.Join(cwd, "skillex-export-"+time.Now().Format("20060102-150405")+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("exporting: %w", err)
+	}
+	return path, nil
+}
+
+// renderLintReport lints the skills at idx, listing only those with findings.
+func renderLintReport(skills []discovery.Skill, idx []int) string {
+	var lines []string
+	clean := 0
+	for _, i := range idx {
+		findings := lint.Check(skills[i])
+		if len(findings) == 0 {
+			clean++
+			continue
+		}
+		lines = append(lines, sectionStyle.Render(fmt.Sprintf("%s (%d)", skills[i].ID(), len(findings))))
+		lines = append(lines, renderLintFindings(findings)...)
+		lines = append(lines, "")
+	}
+	lines = append(lines, fileMetaStyle.Render(fmt.Sprintf("%d of %d skill(s) have no lint findings.", clean, len(idx))))
+	return strings.Join(lines, "\n")
+}
+
+// copyToClipboard uses the system clipboard, falling back to an OSC 52
+// escape sequence that most terminals honor, also over SSH.
+func copyToClipboard(text string) {
+	if err := clipboard.WriteAll(text); err != nil {
+		termenv.Copy(text)
+	}
+}
+
+// listTitle is the skill list's panel title, with the mark count if any.
+func (m Model) listTitle() string {
+	if m.browsing {
+		return fmt.Sprintf("Available (%d not installed)", len(m.available))
+	}
+	if n := len(m.markedIndexes()); n > 0 {
+		return fmt.Sprintf("Skills (%d marked)", n)
+	}
+	return "Skills"
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/smauermann/skillex/internal/config"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/fingerprint"
+)
+
+var changeBadgeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("81")).Bold(true)
+
+// maxSplashChanges bounds the skill and plugin lines listed on the splash
+// screen; the badges in the list show the rest.
+const maxSplashChanges = 6
+
+// refreshFingerprints compares skills with the previous launch and records
+// the current fingerprints next to the state file.
+func refreshFingerprints(stateFile string, skills []discovery.Skill) (fingerprint.Report, error) {
+	if stateFile == "" {
+		return fingerprint.Compare(nil, skills), nil
+	}
+	return fingerprint.Refresh(config.FilesOf(stateFile).Fingerprints(), skills)
+}
+
+// withChanges sets the new/changed badges shown in the list.
+func (m Model) withChanges(r fingerprint.Report) Model {
+	for id, st := range r.Status {
+		m.changes[id] = st
+	}
+	return m
+}
+
+// changeBadge renders the badge for a skill's status since the last launch,
+// or "" when unchanged.
+func changeBadge(st fingerprint.Status) string {
+	if st == fingerprint.Unchanged {
+		return ""
+	}
+	return " " + changeBadgeStyle.Render(st.String())
+}
+
+// renderChangeSummary describes a report for the splash screen, one line per
+// entry, or returns nil when nothing changed.
+func renderChangeSummary(r fingerprint.Report) []string {
+	if r.Empty() {
+		return nil
+	}
+	added, changed := r.Count()
+	var counts []string
+	if added > 0 {
+		counts = append(counts, fmt.Sprintf("%d new", added))
+	}
+	if changed > 0 {
+		counts = append(counts, fmt.Sprintf("%d changed", changed))
+	}
+	if len(r.Removed) > 0 {
+		counts = append(counts, fmt.Sprintf("%d removed", len(r.Removed)))
+	}
+	head := "Since " + r.Since.Local().Format("Jan 2 15:04")
+	if len(counts) > 0 {
+		head += ": " + strings.Join(counts, ", ") + " skill(s)"
+	}
+	lines := []string{changeBadgeStyle.Render(head)}
+
+	var details []string
+	for _, u := range r.Updates {
+		details = append(details, fmt.Sprintf("%s updated %s → %s", u.Plugin, u.From, u.To))
+	}
+	for _, id := range r.Removed {
+		details = append(details, id+" removed")
+	}
+	if len(details) > maxSplashChanges {
+		details = append(details[:maxSplashChanges-1], fmt.Sprintf("and %d more", len(details)-maxSplashChanges+1))
+	}
+	for _, d := range details {
+		lines = append(lines, descStyle.Render(d))
+	}
+	return lines
+}
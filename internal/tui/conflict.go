@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/journal"
+	"github.com/smauermann/skillex/internal/profile"
+)
+
+var conflictTagStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+// Conflict picker option values.
+const (
+	resolveKeepEnabled  = "keep-enabled"
+	resolveKeepDisabled = "keep-disabled"
+	resolveReload       = "reload"
+	resolveReloadToggle = "reload-toggle"
+	resolveDrop         = "drop"
+)
+
+// openConflictPicker offers the ways out of a conflict found while changing
+// the skill at index i.
+func (m Model) openConflictPicker(i int, conflict *discovery.ConflictError) Model {
+	s := m.skills[i]
+	p := &picker{kind: pickConflict, title: "Conflict", target: i}
+
+	switch conflict.Kind {
+	case discovery.ConflictBothExist:
+		enabledPath := filepath.Join(s.Dir(), "SKILL.md")
+		disabledPath := enabledPath + ".disabled"
+		p.note = s.ID() + " has both SKILL.md and\nSKILL.md.disabled. Keep which?\nThe other is renamed to *" + discovery.ConflictSuffix + "."
+		p.options = []pickerOption{
+			{label: "Keep SKILL.md (enabled)", detail: fileSummary(enabledPath), value: resolveKeepEnabled},
+			{label: "Keep SKILL.md.disabled (disabled)", detail: fileSummary(disabledPath), value: resolveKeepDisabled},
+		}
+	case discovery.ConflictModified:
+		p.note = s.ID() + " changed on disk\nsince skillex loaded it."
+		p.options = []pickerOption{
+			{label: "Reload and toggle", detail: "re-read the file, then toggle it", value: resolveReloadToggle},
+			{label: "Reload only", value: resolveReload},
+		}
+	case discovery.ConflictMoved:
+		p.note = s.ID() + " was enabled or disabled\noutside skillex."
+		p.options = []pickerOption{{label: "Reload from disk", value: resolveReload}}
+	default:
+		p.note = s.ID() + " no longer exists on disk."
+		p.options = []pickerOption{{label: "Remove from list", value: resolveDrop}}
+	}
+
+	m.picker = p
+	return m
+}
+
+// fileSummary describes a skill file for choosing between two of them.
+func fileSummary(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "unreadable"
+	}
+	return fmt.Sprintf("%s, modified %s", formatSize(info.Size()), info.ModTime().Local().Format("2006-01-02 15:04"))
+}
+
+// resolveConflict applies the chosen resolution to the skill at index i.
+func (m Model) resolveConflict(i int, choice string) Model {
+	if i < 0 || i >= len(m.skills) {
+		return m
+	}
+	id := m.skills[i].ID()
+
+	switch choice {
+	case resolveDrop:
+		m.skills = append(m.skills[:i:i], m.skills[i+1:]...)
+		m.setStatus("Removed %s from the list", id)
+		return m
+
+	case resolveKeepEnabled, resolveKeepDisabled:
+		resolution := discovery.KeepEnabled
+		if choice == resolveKeepDisabled {
+			resolution = discovery.KeepDisabled
+		}
+		from, to, err := discovery.ResolveConflict(&m.skills[i], resolution)
+		if from != "" {
+			if jerr := m.record("resolve conflict for "+id, journal.Rename(from, to)); jerr != nil && err == nil {
+				err = jerr
+			}
+		}
+		if err != nil {
+			m.setError(err)
+			return m
+		}
+		m.setStatus("Resolved %s; the other file was set aside", id)
+		return m
+	}
+
+	if _, _, err := discovery.ResolveConflict(&m.skills[i], discovery.Reload); err != nil {
+		m.setError(err)
+		return m
+	}
+	if choice != resolveReloadToggle {
+		m.setStatus("Reloaded %s", id)
+		return m
+	}
+	return m.toggleSkill(i)
+}
+
+// record appends actions to the change journal.
+func (m Model) record(summary string, actions ...journal.Action) error {
+	j, err := journal.Open(m.files.Journal())
+	if err != nil {
+		return err
+	}
+	return j.Record(summary, actions...)
+}
+
+// toggleSkill flips the skill at index i and journals it. Conflicts open
+// the resolution picker; other errors go to the status line.
+func (m Model) toggleSkill(i int) Model {
+	verb := "enable"
+	if m.skills[i].Enabled {
+		verb = "disable"
+	}
+	c := profile.Change{Index: i, ID: m.skills[i].ID(), Enable: !m.skills[i].Enabled}
+	err := profile.ApplyRecorded(m.backend, m.skills, []profile.Change{c}, m.files.Journal(), verb+" "+c.ID)
+	var conflict *discovery.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		return m.openConflictPicker(i, conflict)
+	case err != nil:
+		m.setError(err)
+	}
+	return m
+}
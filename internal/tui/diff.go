@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/smauermann/skillex/internal/diff"
+	"github.com/smauermann/skillex/internal/snapshot"
+)
+
+var (
+	diffDeleteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	diffInsertStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("114"))
+	diffHeaderStyle = lipgloss.NewStyle().Bold(true)
+)
+
+// diffPrevious is the diff picker value comparing against the cached
+// previous version; other values are indexes into Model.skills.
+const diffPrevious = "previous"
+
+// captureSnapshots records the skills' text in the snapshot cache next to
+// the state file and keeps the cache for diffs against earlier versions.
+func (m *Model) captureSnapshots() {
+	if m.stateFile == "" {
+		return
+	}
+	cache, err := snapshot.Update(m.files.Snapshots(), m.skills)
+	if err != nil {
+		m.setError(err)
+	}
+	m.snapshots = cache
+}
+
+// openDiff compares the two marked skills, or offers what the selected
+// skill can be compared with: its previous version and skills of the same
+// name from other sources.
+func (m Model) openDiff() Model {
+	if idx := m.markedIndexes(); len(idx) == 2 {
+		return m.diffSkills(idx[0], idx[1])
+	}
+	selected, ok := m.list.SelectedItem().(skillItem)
+	if !ok {
+		return m
+	}
+	target := m.indexOfSkill(selected.skill.Dir())
+
+	p := &picker{kind: pickDiff, title: "Compare " + selected.skill.Name, target: target}
+	if m.snapshots != nil {
+		if prev, ok := m.snapshots.Previous(selected.skill.ID()); ok {
+			p.options = append(p.options, pickerOption{
+				label:  "Previous version",
+				detail: "seen " + prev.SeenAt.Local().Format("2006-01-02 15:04"),
+				value:  diffPrevious,
+			})
+		}
+	}
+	for i, s := range m.skills {
+		if i != target && s.Name == selected.skill.Name {
+			p.options = append(p.options, pickerOption{label: s.ID(), detail: s.Dir(), value: strconv.Itoa(i)})
+		}
+	}
+	if len(p.options) == 0 {
+		m.setStatus("No earlier version or same-named skill to compare with; mark two skills with v to compare them")
+		return m
+	}
+	m.picker = p
+	return m
+}
+
+// diffWith compares skill i with the picker choice.
+func (m Model) diffWith(i int, choice string) Model {
+	if choice != diffPrevious {
+		other, err := strconv.Atoi(choice)
+		if err != nil {
+			return m
+		}
+		return m.diffSkills(other, i)
+	}
+	s := m.skills[i]
+	prev, ok := m.snapshots.Previous(s.ID())
+	if !ok {
+		return m
+	}
+	return m.showDiff(
+		fmt.Sprintf("%s (%s)", s.ID(), prev.SeenAt.Local().Format("2006-01-02 15:04")), s.ID()+" (now)",
+		diff.Document(prev.Frontmatter, prev.Content), diff.Document(s.Frontmatter, s.Content))
+}
+
+// diffSkills compares skill a (old side) with skill b (new side).
+func (m Model) diffSkills(a, b int) Model {
+	sa, sb := m.skills[a], m.skills[b]
+	return m.showDiff(sa.ID(), sb.ID(),
+		diff.Document(sa.Frontmatter, sa.Content), diff.Document(sb.Frontmatter, sb.Content))
+}
+
+// showDiff puts a side-by-side comparison in the preview pane.
+func (m Model) showDiff(oldLabel, newLabel, oldText, newText string) Model {
+	m.report = renderSideBySide(oldLabel, newLabel, diff.Lines(oldText, newText), m.viewport.Width)
+	m.reportTitle = "Diff"
+	m.viewport.SetContent(m.report)
+	m.viewport.GotoTop()
+	return m
+}
+
+// indexOfSkill returns the index in m.skills of the skill in dir, or -1.
+func (m Model) indexOfSkill(dir string) int {
+	for i, s := range m.skills {
+		if s.Dir() == dir {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderSideBySide lays out lines in two columns of the given total width,
+// old on the left, with changed lines colored.
+func renderSideBySide(oldLabel, newLabel string, lines []diff.Line, width int) string {
+	rows := diff.SideBySide(lines)
+	last := 0
+	for _, r := range rows {
+		last = max(last, r.LeftNo, r.RightNo)
+	}
+	numWidth := len(strconv.Itoa(last))
+	col := max((width-3)/2, 20)
+
+	changed := 0
+	for _, r := range rows {
+		if r.Changed() {
+			changed++
+		}
+	}
+	summary := "No differences."
+	if changed > 0 {
+		summary = fmt.Sprintf("%d of %d line(s) differ.", changed, len(rows))
+	}
+
+	sep := fileMetaStyle.Render(" │ ")
+	out := []string{
+		fileMetaStyle.Render(summary),
+		"",
+		diffHeaderStyle.Render(pad(oldLabel, col)) + sep + diffHeaderStyle.Render(pad(newLabel, col)),
+	}
+	for _, r := range rows {
+		left := diffCell(r.LeftNo, r.Left, numWidth, col)
+		right := diffCell(r.RightNo, r.Right, numWidth, col)
+		if r.Changed() {
+			if r.LeftNo != 0 {
+				left = diffDeleteStyle.Render(left)
+			}
+			if r.RightNo != 0 {
+				right = diffInsertStyle.Render(right)
+			}
+		}
+		out = append(out, left+sep+right)
+	}
+	return strings.Join(out, "\n")
+}
+
+// diffCell formats one side of a row: the line number and the text, cut or
+// padded to width. A zero number leaves the side blank.
+func diffCell(no int, text string, numWidth, width int) string {
+	if no == 0 {
+		return strings.Repeat(" ", width)
+	}
+	text = strings.ReplaceAll(text, "\t", "    ")
+	return pad(fmt.Sprintf("%*d %s", numWidth, no, text), width)
+}
+
+// pad cuts s to width cells or fills it with spaces up to width.
+func pad(s string, width int) string {
+	s = ansi.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", width-ansi.StringWidth(s))
+}
@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/lint"
+)
+
+// filePreviewLimit caps how much of a bundled file is read for the preview.
+const filePreviewLimit = 256 * 1024
+
+var (
+	fileDirStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("62")).Bold(true)
+	fileMetaStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	fileExecStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	lintErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	lintWarnStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	sectionStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Bold(true)
+)
+
+// renderLintFindings renders one indented line per finding.
+func renderLintFindings(findings []lint.Finding) []string {
+	lines := make([]string, 0, len(findings))
+	for _, f := range findings {
+		style, mark := lintWarnStyle, "!"
+		if f.Severity == lint.Error {
+			style, mark = lintErrorStyle, "✗"
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("  %s %s", mark, f.Message)))
+	}
+	return lines
+}
+
+// renderFileTree renders the lint summary and the file inventory of a skill as
+// an indented tree. cursor is an index into skill.Files; the returned line is
+// where the cursor was drawn so the caller can scroll it into view.
+func renderFileTree(skill discovery.Skill, cursor int) (content string, cursorLine int) {
+	var lines []string
+
+	if findings := lint.Check(skill); len(findings) > 0 {
+		lines = append(lines, sectionStyle.Render(fmt.Sprintf("Lint (%d)", len(findings))))
+		lines = append(lines, renderLintFindings(findings)...)
+		lines = append(lines, "")
+	}
+
+	count := fmt.Sprint(len(skill.Files))
+	if skill.FilesTruncated {
+		count += "+"
+	}
+	lines = append(lines, sectionStyle.Render(fmt.Sprintf("Files (%s)", count)))
+	if len(skill.Files) == 0 {
+		lines = append(lines, fileMetaStyle.Render("  SKILL.md is the only file in this skill."))
+		return strings.Join(lines, "\n"), 0
+	}
+
+	// Emit a directory header the first time a path enters it. Files are
+	// sorted by path, so siblings are always contiguous.
+	shownDirs := make(map[string]bool)
+	for i, f := range skill.Files {
+		dir := path.Dir(f.Path)
+		if dir != "." {
+			parts := strings.Split(dir, "/")
+			for d := range parts {
+				sub := strings.Join(parts[:d+1], "/")
+				if shownDirs[sub] {
+					continue
+				}
+				shownDirs[sub] = true
+				lines = append(lines, "  "+strings.Repeat("  ", d)+fileDirStyle.Render(parts[d]+"/"))
+			}
+		}
+
+		depth := strings.Count(f.Path, "/")
+		prefix := "  "
+		nameStyle := normalTitleStyle
+		if i == cursor {
+			prefix = cursorStyle.Render("> ")
+			nameStyle = selectedTitleStyle
+			cursorLine = len(lines)
+		}
+
+		meta := fileMetaStyle.Render(fmt.Sprintf("  %s  %s", formatSize(f.Size), f.Type))
+		if f.Executable {
+			meta += fileExecStyle.Render("  exec")
+		}
+		lines = append(lines, prefix+strings.Repeat("  ", depth)+nameStyle.Render(path.Base(f.Path))+meta)
+	}
+	if skill.FilesTruncated {
+		lines = append(lines, fileExecStyle.Render(fmt.Sprintf("  … truncated: only the first %d files are listed", discovery.MaxFiles)))
+	}
+
+	return strings.Join(lines, "\n"), cursorLine
+}
+
+// filePreviewMarkdown loads a bundled file and returns markdown suitable for
+// glamour: markdown files as-is, other text files as a fenced code block.
+func filePreviewMarkdown(skill discovery.Skill, f discovery.SkillFile) string {
+	if f.Type == discovery.FileBinary {
+		return fmt.Sprintf("**%s**\n\nBinary file, %s.", f.Path, formatSize(f.Size))
+	}
+
+	data, err := readHead(filepath.Join(skill.Dir(), filepath.FromSlash(f.Path)), filePreviewLimit)
+	if err != nil {
+		return fmt.Sprintf("**%s**\n\nCould not read file: %v", f.Path, err)
+	}
+
+	var md strings.Builder
+	ext := strings.ToLower(path.Ext(f.Path))
+	if ext == ".md" || ext == ".markdown" {
+		md.WriteString(string(data))
+	} else {
+		fence := "```"
+		for strings.Contains(string(data), fence) {
+			fence += "`"
+		}
+		fmt.Fprintf(&md, "%s%s\n%s\n%s\n", fence, strings.TrimPrefix(ext, "."), strings.TrimRight(string(data), "\n"), fence)
+	}
+	if f.Size > filePreviewLimit {
+		fmt.Fprintf(&md, "\n\n*Preview truncated at %s of %s.*", formatSize(filePreviewLimit), formatSize(f.Size))
+	}
+	return md.String()
+}
+
+// readHead reads at most limit bytes from the start of a file.
+func readHead(path string, limit int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, limit)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// formatSize renders a byte count in a compact human-readable form.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
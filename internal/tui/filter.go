@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"sync/atomic"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/query"
+)
+
+// skillIndex lets the list filter, which only sees FilterValue strings, look
+// up the skills behind them. The list filters in a background command, so
+// the map is swapped atomically rather than mutated.
+type skillIndex struct {
+	skills atomic.Pointer[map[string]discovery.Skill]
+}
+
+// set replaces the indexed skills, keyed by directory like FilterValue.
+func (ix *skillIndex) set(skills []discovery.Skill) {
+	byDir := make(map[string]discovery.Skill, len(skills))
+	for _, s := range skills {
+		byDir[s.Dir()] = s
+	}
+	ix.skills.Store(&byDir)
+}
+
+// filter is the list's FilterFunc. Plain words keep the fuzzy match on name
+// and plugin; queries with fields, such as status:disabled, are evaluated
+// with the query package. An invalid query matches nothing.
+func (ix *skillIndex) filter(term string, targets []string) []list.Rank {
+	q, err := query.Parse(term)
+	if err != nil {
+		return nil
+	}
+	byDir := *ix.skills.Load()
+
+	if !q.Structured() {
+		labels := make([]string, len(targets))
+		for i, t := range targets {
+			if s, ok := byDir[t]; ok {
+				labels[i] = s.Name + " " + s.Plugin
+			}
+		}
+		return list.DefaultFilter(term, labels)
+	}
+
+	var ranks []list.Rank
+	for i, t := range targets {
+		if s, ok := byDir[t]; ok && q.Match(s) {
+			ranks = append(ranks, list.Rank{Index: i})
+		}
+	}
+	return ranks
+}
+
+// filterError returns the parse error of the filter being typed, if any.
+func (m Model) filterError() error {
+	if m.list.FilterState() == list.Unfiltered {
+		return nil
+	}
+	_, err := query.Parse(m.list.FilterValue())
+	return err
+}
@@ -0,0 +1,54 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/journal"
+)
+
+// toggleSelected flips the skill under the cursor.
+func (m Model) toggleSelected() (Model, tea.Cmd) {
+	si, ok := m.list.SelectedItem().(skillItem)
+	if !ok {
+		return m, nil
+	}
+	for i := range m.skills {
+		if m.skills[i].FilePath == si.skill.FilePath {
+			return m.toggleSkill(i).syncItems()
+		}
+	}
+	return m, nil
+}
+
+// stepJournal undoes or redoes one journal entry, then re-reads every
+// skill's enabled state from disk.
+func (m Model) stepJournal(step func(*journal.Journal) (journal.Entry, error), done string) (Model, tea.Cmd) {
+	j, err := journal.Open(m.files.Journal())
+	if err != nil {
+		m.setError(err)
+		return m, nil
+	}
+	e, err := step(j)
+	if err != nil {
+		m.setError(err)
+		return m, nil
+	}
+	m.setStatus("%s: %s", done, e.Summary)
+	for i := range m.skills {
+		if err := discovery.Restat(&m.skills[i]); err != nil {
+			m.setError(err)
+		}
+	}
+	m.backend.Mark(m.skills)
+	return m.syncItems()
+}
+
+// undo reverts the most recent journaled change.
+func (m Model) undo() (Model, tea.Cmd) {
+	return m.stepJournal((*journal.Journal).Undo, "Undid")
+}
+
+// redo re-applies the most recently undone change.
+func (m Model) redo() (Model, tea.Cmd) {
+	return m.stepJournal((*journal.Journal).Redo, "Redid")
+}
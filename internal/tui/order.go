@@ -0,0 +1,260 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// sortOrder is how the skill list is ordered.
+type sortOrder int
+
+const (
+	// sortPlugin groups skills under collapsible plugin headers, in
+	// discovery order.
+	sortPlugin sortOrder = iota
+	// sortMarketplace groups skills under collapsible marketplace headers.
+	sortMarketplace
+	sortStatus
+	sortActivation
+	sortDescLength
+	sortModified
+)
+
+// sortOrders describes each order; name is what the state file stores.
+var sortOrders = []struct {
+	name, label, detail string
+}{
+	sortPlugin:      {"plugin", "Plugin", "grouped; space collapses a group"},
+	sortMarketplace: {"marketplace", "Marketplace", "grouped by where plugins were installed from"},
+	sortStatus:      {"status", "Enabled state", "enabled first"},
+	sortActivation:  {"activation", "Activation style", "directive, passive, then unknown"},
+	sortDescLength:  {"description", "Description length", "longest first, the biggest budget users"},
+	sortModified:    {"modified", "Last modified", "most recently changed first"},
+}
+
+// parseSortOrder maps a stored name to an order, defaulting to sortPlugin.
+func parseSortOrder(name string) sortOrder {
+	for i, o := range sortOrders {
+		if o.name == name {
+			return sortOrder(i)
+		}
+	}
+	return sortPlugin
+}
+
+var groupHeaderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("62")).Bold(true)
+
+// groupHeader is a list row naming a plugin or marketplace in the grouped
+// orders. key identifies the group in collapsed, see groupKey.
+type groupHeader struct {
+	key       string
+	label     string
+	count     int
+	enabled   int
+	collapsed bool
+}
+
+// FilterValue is empty so headers drop out while filtering.
+func (h groupHeader) FilterValue() string { return "" }
+
+func (h groupHeader) render(w io.Writer, selected bool) {
+	arrow := "▾"
+	if h.collapsed {
+		arrow = "▸"
+	}
+	prefix := "  "
+	if selected {
+		prefix = cursorStyle.Render("> ")
+	}
+	fmt.Fprintf(w, "%s%s\n  %s", prefix, groupHeaderStyle.Render(arrow+" "+h.label),
+		normalDescStyle.Render(fmt.Sprintf("%d skills, %d enabled", h.count, h.enabled)))
+}
+
+// items builds the list rows for the current order. Collapsed groups are
+// expanded while a filter is applied so every skill stays searchable.
+func (m Model) items() []list.Item {
+	skills := m.listSkills()
+	m.index.set(skills)
+	order := make([]int, len(skills))
+	for i := range order {
+		order[i] = i
+	}
+	less := m.sortLess()
+	if less != nil {
+		sort.SliceStable(order, func(a, b int) bool { return less(skills[order[a]], skills[order[b]]) })
+	}
+
+	if m.sort != sortPlugin && m.sort != sortMarketplace {
+		items := make([]list.Item, len(order))
+		for n, i := range order {
+			items[n] = skillItem{skill: skills[i]}
+		}
+		return items
+	}
+
+	// Group in order of first appearance, which keeps discovery's plugin
+	// order and puts local skills last.
+	var keys []string
+	labels := make(map[string]string)
+	groups := make(map[string][]discovery.Skill)
+	for _, i := range order {
+		s := skills[i]
+		key := m.groupKey(s)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+			labels[key] = groupLabel(m.sort, s)
+		}
+		groups[key] = append(groups[key], s)
+	}
+
+	filtering := m.list.FilterState() != list.Unfiltered
+	var items []list.Item
+	for _, key := range keys {
+		h := groupHeader{key: key, label: labels[key], count: len(groups[key]), collapsed: m.collapsed[key] && !filtering}
+		for _, s := range groups[key] {
+			if s.Enabled {
+				h.enabled++
+			}
+		}
+		items = append(items, h)
+		if h.collapsed {
+			continue
+		}
+		for _, s := range groups[key] {
+			items = append(items, skillItem{skill: s})
+		}
+	}
+	return items
+}
+
+// groupKey returns the group a skill falls in under the current order. The
+// plugin and marketplace orders share the collapsed set, so marketplace keys
+// start with "@" to keep them apart from plugin names.
+func (m Model) groupKey(s discovery.Skill) string {
+	if m.sort == sortMarketplace {
+		return "@" + s.Marketplace
+	}
+	return s.Plugin
+}
+
+// groupLabel is the header text of a skill's group.
+func groupLabel(order sortOrder, s discovery.Skill) string {
+	if order != sortMarketplace {
+		return s.Plugin
+	}
+	label := s.Marketplace
+	if label == "" {
+		label = "local skills"
+	}
+	if s.MarketplaceInfo != nil && s.MarketplaceInfo.Official() {
+		label += " (official)"
+	}
+	return label
+}
+
+// updateList forwards msg to the list. When a filter is applied or cleared
+// the rows are rebuilt, so collapsed groups open up for searching.
+func (m Model) updateList(msg tea.Msg) (Model, tea.Cmd) {
+	wasUnfiltered := m.list.FilterState() == list.Unfiltered
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	if wasUnfiltered != (m.list.FilterState() == list.Unfiltered) && len(m.collapsed) > 0 {
+		cmd = tea.Batch(cmd, m.list.SetItems(m.items()))
+	}
+	return m, cmd
+}
+
+// sortLess returns the comparison for the current order, or nil to keep
+// discovery order.
+func (m Model) sortLess() func(a, b discovery.Skill) bool {
+	switch m.sort {
+	case sortStatus:
+		return func(a, b discovery.Skill) bool { return a.Enabled && !b.Enabled }
+	case sortActivation:
+		rank := map[discovery.ActivationStyle]int{
+			discovery.ActivationDirective: 0,
+			discovery.ActivationPassive:   1,
+			discovery.ActivationNeutral:   2,
+		}
+		return func(a, b discovery.Skill) bool { return rank[a.ActivationStyle] < rank[b.ActivationStyle] }
+	case sortDescLength:
+		return func(a, b discovery.Skill) bool { return len(a.Description) > len(b.Description) }
+	case sortModified:
+		return func(a, b discovery.Skill) bool { return a.ModTime.After(b.ModTime) }
+	}
+	return nil
+}
+
+// openSortPicker lists the available orders.
+func (m Model) openSortPicker() Model {
+	p := &picker{kind: pickSort, title: "Sort by", cursor: int(m.sort)}
+	for i, o := range sortOrders {
+		p.options = append(p.options, pickerOption{label: o.label, detail: o.detail, value: sortOrders[i].name})
+	}
+	m.picker = p
+	return m
+}
+
+// setSort switches the list order and persists it.
+func (m Model) setSort(name string) Model {
+	m.sort = parseSortOrder(name)
+	m.saveUI()
+	m.setStatus("Sorted by %s", sortOrders[m.sort].label)
+	return m
+}
+
+// toggleGroup collapses or expands the group under the cursor. It
+// reports false when the cursor is not on a group header.
+func (m Model) toggleGroup() (Model, bool) {
+	h, ok := m.list.SelectedItem().(groupHeader)
+	if !ok {
+		return m, false
+	}
+	if m.collapsed[h.key] {
+		delete(m.collapsed, h.key)
+	} else {
+		m.collapsed[h.key] = true
+	}
+	m.saveUI()
+	return m, true
+}
+
+// saveUI persists the list order and collapsed groups. Failing to save a
+// view preference is reported but otherwise harmless.
+func (m *Model) saveUI() {
+	if m.stateFile == "" {
+		return
+	}
+	ui := &state.UI{Sort: sortOrders[m.sort].name}
+	for p := range m.collapsed {
+		ui.Collapsed = append(ui.Collapsed, p)
+	}
+	sort.Strings(ui.Collapsed)
+	if err := ui.Save(m.files.UI()); err != nil {
+		m.setError(err)
+	}
+}
+
+// loadUI restores the persisted list order and collapsed groups.
+func (m *Model) loadUI() {
+	m.collapsed = make(map[string]bool)
+	if m.stateFile == "" {
+		return
+	}
+	ui, err := state.LoadUI(m.files.UI())
+	if err != nil {
+		m.setError(err)
+		return
+	}
+	m.sort = parseSortOrder(ui.Sort)
+	for _, p := range ui.Collapsed {
+		m.collapsed[p] = true
+	}
+}
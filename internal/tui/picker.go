@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/smauermann/skillex/internal/profile"
+	"github.com/smauermann/skillex/internal/state"
+)
+
+// pickerKind identifies what choosing a picker option does.
+type pickerKind int
+
+const (
+	pickProfile pickerKind = iota
+	pickConflict
+	pickBulk
+	pickSort
+	pickDiff
+)
+
+// picker is a small menu rendered in place of the skill list while open.
+type picker struct {
+	kind    pickerKind
+	title   string
+	options []pickerOption
+	cursor  int
+	// note is shown above the options.
+	note string
+	// empty is shown when there are no options.
+	empty string
+	// target is the index in Model.skills the picker acts on, if any.
+	target int
+}
+
+type pickerOption struct {
+	label  string
+	detail string
+	value  string
+}
+
+// move shifts the cursor by delta, clamped to the options.
+func (p *picker) move(delta int) {
+	p.cursor += delta
+	if p.cursor >= len(p.options) {
+		p.cursor = len(p.options) - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+}
+
+// selected returns the option under the cursor.
+func (p picker) selected() (pickerOption, bool) {
+	if len(p.options) == 0 {
+		return pickerOption{}, false
+	}
+	return p.options[p.cursor], true
+}
+
+func (p picker) view() string {
+	if len(p.options) == 0 {
+		return fileMetaStyle.Render(p.empty)
+	}
+
+	var lines []string
+	if p.note != "" {
+		lines = append(lines, p.note, "")
+	}
+	for i, o := range p.options {
+		prefix, style := "  ", normalTitleStyle
+		if i == p.cursor {
+			prefix, style = cursorStyle.Render("> "), selectedTitleStyle
+		}
+		lines = append(lines, prefix+style.Render(o.label))
+		if o.detail != "" {
+			lines = append(lines, "  "+normalDescStyle.Render(o.detail))
+		}
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// openProfilePicker lists saved profiles with what applying each would change.
+func (m Model) openProfilePicker() Model {
+	p := &picker{
+		kind:  pickProfile,
+		title: "Profiles",
+		empty: "No saved profiles.\n\nSave one from the shell with\n'skillex profile save <name>'.",
+	}
+
+	st, err := state.Load(m.stateFile)
+	if err != nil {
+		m.setError(err)
+		return m
+	}
+
+	names := make([]string, 0, len(st.Profiles))
+	for name := range st.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		plan := profile.Diff(m.skills, st.Profiles[name].Skills)
+		enabled, disabled := profile.Summary(plan.Changes)
+		detail := "matches current state"
+		if enabled+disabled > 0 {
+			detail = fmt.Sprintf("+%d enabled  -%d disabled", enabled, disabled)
+		}
+		if len(plan.Missing) > 0 {
+			detail += fmt.Sprintf("  %d missing", len(plan.Missing))
+		}
+		p.options = append(p.options, pickerOption{label: name, detail: detail, value: name})
+	}
+
+	m.picker = p
+	return m
+}
+
+// applyProfile applies the named profile and reports the outcome in the
+// status line.
+func (m Model) applyProfile(name string) Model {
+	st, err := state.Load(m.stateFile)
+	if err != nil {
+		m.setError(err)
+		return m
+	}
+	p, ok := st.Profiles[name]
+	if !ok {
+		m.setError(fmt.Errorf("no profile named %q", name))
+		return m
+	}
+
+	plan := profile.Diff(m.skills, p.Skills)
+	err = profile.ApplyRecorded(m.backend, m.skills, plan.Changes, m.files.Journal(), fmt.Sprintf("apply profile %q", name))
+	switch {
+	case errors.Is(err, profile.ErrNotJournaled):
+		m.setError(fmt.Errorf("applied profile %q: %w", name, err))
+		return m
+	case err != nil:
+		m.setError(fmt.Errorf("applying profile %q failed, no changes made: %w", name, err))
+		return m
+	}
+	enabled, disabled := profile.Summary(plan.Changes)
+	m.setStatus("Applied profile %q: enabled %d, disabled %d", name, enabled, disabled)
+	return m
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// renderPluginDetails renders the Plugin tab: the manifest of the plugin the
+// skill belongs to, where it is installed and how many of its skills are
+// enabled.
+func renderPluginDetails(skill discovery.Skill, skills []discovery.Skill, width int) string {
+	if skill.PluginDir == "" {
+		return sectionStyle.Render(skill.Plugin) + "\n\n" +
+			fileMetaStyle.Render("A local skill folder, not a plugin: there is no manifest.\n"+skill.Dir())
+	}
+
+	m := skill.Manifest
+	title := skill.Plugin
+	if m != nil && m.Name != "" && m.Name != skill.Plugin {
+		title += " (" + m.Name + ")"
+	}
+	if skill.PluginVersion != "" {
+		title += " " + skill.PluginVersion
+	}
+	lines := []string{sectionStyle.Render(title)}
+	if m != nil && m.Description != "" {
+		lines = append(lines, normalTitleStyle.Width(max(width, 20)).Render(m.Description))
+	}
+	lines = append(lines, "")
+
+	row := func(label, value string) {
+		if value != "" {
+			lines = append(lines, analyticsLabelStyle.Render(label)+value)
+		}
+	}
+	if m != nil {
+		row("Author", m.Author.String())
+		row("Homepage", m.Homepage)
+		row("Repository", string(m.Repository))
+		row("License", m.License)
+		row("Keywords", strings.Join(m.Keywords, ", "))
+	} else {
+		lines = append(lines, fileMetaStyle.Render("No "+discovery.ManifestPath+": the plugin is named after its key."))
+	}
+
+	total, enabled := 0, 0
+	for _, s := range skills {
+		if s.PluginDir == skill.PluginDir {
+			total++
+			if s.Enabled {
+				enabled++
+			}
+		}
+	}
+	row("Skills", fmt.Sprintf("%d of %d enabled", enabled, total))
+	if skill.Marketplace != "" {
+		market := skill.Marketplace
+		if info := skill.MarketplaceInfo; info == nil {
+			market += fileMetaStyle.Render("  not in " + discovery.MarketplacesFileName)
+		} else if info.Official() {
+			market += lipgloss.NewStyle().Foreground(directiveColor).Render("  official")
+		} else if info.Local() {
+			market += fileMetaStyle.Render("  local")
+		}
+		row("Marketplace", market)
+		if skill.MarketplaceInfo != nil {
+			row("Source", skill.MarketplaceInfo.Source)
+		}
+	}
+	if skill.Available {
+		row("Not installed", skill.PluginDir)
+		row("Install with", "skillex plugin install "+skill.Plugin+"@"+skill.Marketplace)
+	} else {
+		row("Installed at", skill.PluginDir)
+	}
+	row("Commit", shortSha(skill.GitCommitSha))
+
+	if m != nil {
+		var components []string
+		for _, c := range []struct {
+			name  string
+			paths []string
+		}{
+			{"commands", m.Commands}, {"agents", m.Agents}, {"skills", m.Skills}, {"hooks", m.Hooks}, {"mcpServers", m.MCPServers},
+		} {
+			if len(c.paths) > 0 {
+				components = append(components, fmt.Sprintf("%-11s %s", c.name, strings.Join(c.paths, ", ")))
+			}
+		}
+		if len(components) > 0 {
+			lines = append(lines, "", sectionStyle.Render("Declared paths"))
+			for _, c := range components {
+				lines = append(lines, "  "+fileMetaStyle.Render(c))
+			}
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// shortSha abbreviates a commit hash for display.
+func shortSha(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
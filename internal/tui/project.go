@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/smauermann/skillex/internal/backend"
+	"github.com/smauermann/skillex/internal/profile"
+)
+
+var bannerStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("232")).
+	Background(lipgloss.Color("214")).
+	Padding(0, 1)
+
+// projectDrift returns the changes needed to match the project file, or nil
+// outside a project with a skillex.yaml.
+func (m Model) projectDrift() []profile.Change {
+	if m.project == nil {
+		return nil
+	}
+	return m.project.Plan(m.skills).Changes
+}
+
+// bannerLines returns the warnings shown above the panes, one per line:
+// drift from the project file and skills re-enabled by plugin updates.
+func (m Model) bannerLines() []string {
+	var lines []string
+	if drift := m.projectDrift(); len(drift) > 0 {
+		enabled, disabled := profile.Summary(drift)
+		lines = append(lines, fmt.Sprintf(
+			"%d skill(s) differ from %s (%d to enable, %d to disable). Press S to sync.",
+			len(drift), profile.ProjectFileName, enabled, disabled))
+	}
+	if reverted := m.revertedSkills(); len(reverted) > 0 {
+		lines = append(lines, fmt.Sprintf(
+			"%d disabled skill(s) were re-enabled, likely by a plugin update. Press R to disable them again.",
+			len(reverted)))
+	}
+	return lines
+}
+
+// bannerHeight is the number of rows the banner takes.
+func (m Model) bannerHeight() int {
+	return len(m.bannerLines())
+}
+
+// banner renders bannerLines, or "" when there is nothing to warn about.
+func (m Model) banner() string {
+	lines := m.bannerLines()
+	for i, line := range lines {
+		lines[i] = bannerStyle.Width(m.width).Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// revertedSkills returns the IDs of skills marked Reverted by the backend.
+func (m Model) revertedSkills() []string {
+	var ids []string
+	for _, s := range m.skills {
+		if s.Reverted && s.Enabled {
+			ids = append(ids, s.ID())
+		}
+	}
+	return ids
+}
+
+// reapply disables again the skills a plugin update re-enabled.
+func (m Model) reapply() (Model, tea.Cmd) {
+	n := len(m.revertedSkills())
+	actions, err := backend.Reapply(m.backend, m.skills)
+	rerr := profile.Record(m.files.Journal(), "reapply disabled skills", actions)
+	switch {
+	case err != nil:
+		if rerr != nil {
+			err = fmt.Errorf("%w; %w", err, rerr)
+		}
+		m.setError(fmt.Errorf("reapply: %w", err))
+	case errors.Is(rerr, profile.ErrNotJournaled):
+		m.setError(fmt.Errorf("disabled %d reverted skill(s) again: %w", n, rerr))
+	default:
+		m.setStatus("Disabled %d reverted skill(s) again", n)
+	}
+	return m.syncItems()
+}
+
+// syncProject applies the project file and reports the outcome.
+func (m Model) syncProject() (Model, tea.Cmd) {
+	changes := m.projectDrift()
+	if len(changes) == 0 {
+		m.setStatus("Skills already match %s", profile.ProjectFileName)
+		return m, nil
+	}
+	err := profile.ApplyRecorded(m.backend, m.skills, changes, m.files.Journal(), "sync with "+profile.ProjectFileName)
+	switch {
+	case errors.Is(err, profile.ErrNotJournaled):
+		m.setError(fmt.Errorf("synced with %s: %w", profile.ProjectFileName, err))
+	case err != nil:
+		m.setError(fmt.Errorf("sync failed, no changes made: %w", err))
+	default:
+		enabled, disabled := profile.Summary(changes)
+		m.setStatus("Synced with %s: enabled %d, disabled %d", profile.ProjectFileName, enabled, disabled)
+	}
+	return m.syncItems()
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/smauermann/skillex/internal/search"
+)
+
+var searchHighlightStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("0")).
+	Background(lipgloss.Color("220"))
+
+// searchMode is the full-text search shown in place of the skill list.
+type searchMode struct {
+	input   textinput.Model
+	results []search.Result
+	cursor  int
+}
+
+// openSearch starts a full-text search, prefilled with the last term.
+func (m Model) openSearch() (Model, tea.Cmd) {
+	input := textinput.New()
+	input.Prompt = "? "
+	input.Placeholder = "search skill text"
+	input.SetValue(m.searchTerm)
+	s := &searchMode{input: input}
+	s.results = m.searchIndex.Search(m.searchTerm)
+	m.search = s
+	return m, s.input.Focus()
+}
+
+// updateSearch handles keys while the search is open. Every edit reruns the
+// query against the index.
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := m.search
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.search = nil
+		return m, nil
+	case "down", "ctrl+n":
+		if s.cursor < len(s.results)-1 {
+			s.cursor++
+		}
+		return m, nil
+	case "up", "ctrl+p":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+		return m, nil
+	case "enter":
+		if len(s.results) == 0 {
+			return m, nil
+		}
+		m.search = nil
+		return m.openSearchResult(s.results[s.cursor].Index, s.input.Value())
+	}
+
+	before := s.input.Value()
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	if s.input.Value() != before {
+		s.results = m.searchIndex.Search(s.input.Value())
+		s.cursor = 0
+	}
+	return m, cmd
+}
+
+// openSearchResult selects skill i in the list and shows its SKILL.md with
+// the words of term highlighted, scrolled to the first hit.
+func (m Model) openSearchResult(i int, term string) (Model, tea.Cmd) {
+	skill := m.skills[i]
+	m.browsing = false
+	m.list.ResetFilter()
+	if key := m.groupKey(skill); m.collapsed[key] {
+		delete(m.collapsed, key)
+		m.saveUI()
+	}
+	cmd := m.list.SetItems(m.items())
+	for n, item := range m.list.Items() {
+		if s, ok := item.(skillItem); ok && s.skill.Dir() == skill.Dir() {
+			m.list.Select(n)
+			break
+		}
+	}
+
+	m.searchTerm = term
+	m.tab = tabSkill
+	m.fileCursor, m.fileOpen = 0, false
+	m.report = ""
+	m.focusViewport = true
+	m = m.updateViewportContent()
+	if len(m.searchLines) > 0 {
+		m.searchHit = -1
+		m = m.jumpSearchHit(1)
+	}
+	return m, cmd
+}
+
+// jumpSearchHit scrolls the preview to the next (delta 1) or previous
+// (delta -1) line containing a search hit, wrapping around.
+func (m Model) jumpSearchHit(delta int) Model {
+	n := len(m.searchLines)
+	if n == 0 {
+		m.setStatus("No matches for %q in this view", m.searchTerm)
+		return m
+	}
+	if m.searchHit < 0 && delta < 0 {
+		// Nothing visited yet: N starts from the last hit.
+		m.searchHit = 0
+	}
+	m.searchHit = ((m.searchHit+delta)%n + n) % n
+	m.viewport.SetYOffset(m.searchLines[m.searchHit])
+	m.setStatus("Match %d/%d for %q", m.searchHit+1, n, m.searchTerm)
+	return m
+}
+
+// clearSearch drops the preview highlighting.
+func (m Model) clearSearch() Model {
+	m.searchTerm = ""
+	m.searchLines = nil
+	return m.updateViewportContent()
+}
+
+// view renders the input and the results that fit in height rows, keeping
+// the cursor on screen.
+func (s searchMode) view(m Model, width, height int) string {
+	lines := []string{s.input.View(), ""}
+	term := s.input.Value()
+	if len(search.Tokenize(term)) == 0 {
+		lines = append(lines, fileMetaStyle.Render("Matches names, descriptions and SKILL.md bodies."))
+		return strings.Join(lines, "\n")
+	}
+	if len(s.results) == 0 {
+		lines = append(lines, fileMetaStyle.Render("No matches."))
+		return strings.Join(lines, "\n")
+	}
+
+	re := needlePattern(search.Tokenize(term))
+	blocks := make([][]string, len(s.results))
+	for i, r := range s.results {
+		skill := m.skills[r.Index]
+		prefix, style := "  ", normalTitleStyle
+		if i == s.cursor {
+			prefix, style = cursorStyle.Render("> "), selectedTitleStyle
+		}
+		hits := "match"
+		if r.Count != 1 {
+			hits = "matches"
+		}
+		block := []string{prefix + style.Render(skill.ID()) + " " + normalDescStyle.Render(fmt.Sprintf("%d %s", r.Count, hits))}
+		for _, snip := range r.Snippets {
+			snip = ansi.Truncate(snip, max(width-4, 10), "…")
+			hl, _ := highlightLines(snip, re, searchHighlightStyle)
+			block = append(block, "    "+hl)
+		}
+		blocks[i] = append(block, "")
+	}
+
+	// Drop leading results until the one under the cursor fits.
+	room := height - len(lines)
+	start, used := 0, 0
+	for i := 0; i <= s.cursor; i++ {
+		used += len(blocks[i])
+	}
+	for used > room && start < s.cursor {
+		used -= len(blocks[start])
+		start++
+	}
+	for _, block := range blocks[start:] {
+		if len(lines)+len(block) > height {
+			break
+		}
+		lines = append(lines, block...)
+	}
+	return strings.Join(lines, "\n")
+}
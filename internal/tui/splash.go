@@ -6,7 +6,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/smauermann/skillex/internal/config"
 	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/fingerprint"
 )
 
 const logo = `
@@ -31,29 +33,45 @@ var (
 			Italic(true)
 )
 
-// skillsLoadedMsg is sent when skill discovery completes.
+// skillsLoadedMsg is sent when skill discovery completes. changes compares
+// the skills with the previous launch; changesErr is a failure to do so,
+// which does not stop skillex from starting. available are the skills of
+// uninstalled plugins in local marketplace clones.
 type skillsLoadedMsg struct {
-	skills []discovery.Skill
-	err    error
+	skills     []discovery.Skill
+	available  []discovery.Skill
+	err        error
+	changes    fingerprint.Report
+	changesErr error
 }
 
 // SplashModel shows a splash screen until the user presses Enter.
 type SplashModel struct {
 	pluginsFile  string
 	localDirs    []discovery.LocalSkillsDir
+	stateFile    string
+	projectFile  string
+	exclude      []string
 	styleOpt     glamour.TermRendererOption
 	width        int
 	height       int
 	err          error
 	skills       []discovery.Skill
 	skillsLoaded bool
+	changes      fingerprint.Report
+	changesErr   error
+	available    []discovery.Skill
 }
 
-// NewSplash creates the splash screen model.
-func NewSplash(pluginsFile string, localDirs []discovery.LocalSkillsDir, styleOpt glamour.TermRendererOption) SplashModel {
+// NewSplash creates the splash screen model. Skills whose ID or directory
+// matches one of the exclude patterns are hidden, as config.Filter does.
+func NewSplash(pluginsFile string, localDirs []discovery.LocalSkillsDir, exclude []string, stateFile, projectFile string, styleOpt glamour.TermRendererOption) SplashModel {
 	return SplashModel{
 		pluginsFile: pluginsFile,
 		localDirs:   localDirs,
+		exclude:     exclude,
+		stateFile:   stateFile,
+		projectFile: projectFile,
 		styleOpt:    styleOpt,
 	}
 }
@@ -64,8 +82,20 @@ func (m SplashModel) Init() tea.Cmd {
 
 func (m SplashModel) discoverSkills() tea.Cmd {
 	return func() tea.Msg {
-		skills, err := discovery.Discover(m.pluginsFile, m.localDirs)
-		return skillsLoadedMsg{skills: skills, err: err}
+		cache := discovery.OpenCache(config.FilesOf(m.stateFile).DiscoveryCache())
+		skills, _, err := discovery.DiscoverCached(m.pluginsFile, m.localDirs, cache)
+		if err != nil {
+			return skillsLoadedMsg{err: err}
+		}
+		skills = config.Filter(skills, m.exclude)
+		changes, changesErr := refreshFingerprints(m.stateFile, skills)
+		// Browsing marketplaces is an extra; a clone that cannot be read
+		// only leaves its plugins out of the Available list.
+		available, _, _ := discovery.DiscoverAvailable(m.pluginsFile, cache)
+		available = config.Filter(available, m.exclude)
+		// The cache only saves time on the next launch.
+		_ = cache.Save()
+		return skillsLoadedMsg{skills: skills, available: available, changes: changes, changesErr: changesErr}
 	}
 }
 
@@ -77,7 +107,10 @@ func (m SplashModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "enter":
 			if m.skillsLoaded && len(m.skills) > 0 {
-				mainModel := New(m.skills, m.styleOpt)
+				mainModel := New(m.skills, m.stateFile, m.projectFile, m.styleOpt).withChanges(m.changes).withAvailable(m.available)
+				if m.changesErr != nil {
+					mainModel.setError(m.changesErr)
+				}
 				return mainModel.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
 			}
 		}
@@ -97,6 +130,8 @@ func (m SplashModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.skills = msg.skills
 		m.skillsLoaded = true
+		m.changes, m.changesErr = msg.changes, msg.changesErr
+		m.available = msg.available
 	}
 
 	return m, nil
@@ -121,13 +156,13 @@ func (m SplashModel) View() string {
 		prompt = promptStyle.Render("Loading skills...")
 	}
 
-	content := lipgloss.JoinVertical(lipgloss.Center,
-		logoRendered,
-		"",
-		desc,
-		"",
-		prompt,
-	)
+	parts := []string{logoRendered, "", desc, ""}
+	if summary := renderChangeSummary(m.changes); summary != nil {
+		parts = append(parts, summary...)
+		parts = append(parts, "")
+	}
+	parts = append(parts, prompt)
+	content := lipgloss.JoinVertical(lipgloss.Center, parts...)
 
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
 }
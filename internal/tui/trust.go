@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/smauermann/skillex/internal/audit"
+	"github.com/smauermann/skillex/internal/discovery"
+)
+
+// injectionHighlightStyle marks suspicious spans in the rendered preview.
+var injectionHighlightStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("231")).
+	Background(lipgloss.Color("124")).
+	Bold(true)
+
+// skillInjections scans everything Claude reads from a skill on invocation:
+// the frontmatter and the body.
+func skillInjections(skill discovery.Skill) []audit.Injection {
+	return audit.ScanInjection(skill.Frontmatter + "\n" + skill.Content)
+}
+
+// renderTrustLine summarizes prompt-injection findings for the analytics panel.
+func renderTrustLine(injections []audit.Injection) string {
+	label := analyticsLabelStyle.Render("Trust")
+	if len(injections) == 0 {
+		return label + lipgloss.NewStyle().Foreground(directiveColor).Render("No prompt-injection patterns")
+	}
+
+	var kinds []string
+	seen := make(map[audit.InjectionKind]bool)
+	for _, inj := range injections {
+		if !seen[inj.Kind] {
+			seen[inj.Kind] = true
+			kinds = append(kinds, inj.Kind.String())
+		}
+	}
+	return label +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(
+			fmt.Sprintf("%d suspicious span(s): %s", len(injections), strings.Join(kinds, ", "))) +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" · highlighted in preview")
+}
+
+// revealInjections prepares preview markdown so suspicious spans survive
+// rendering: invisible characters are replaced with visible ⟦U+XXXX⟧ markers.
+// It returns the new markdown and the texts to highlight after rendering.
+func revealInjections(md string) (string, []string) {
+	injections := audit.ScanInjection(md)
+	if len(injections) == 0 {
+		return md, nil
+	}
+
+	var needles []string
+	for _, inj := range injections {
+		if inj.Kind == audit.InjectionOverride || inj.Kind == audit.InjectionExfiltration {
+			// glamour reflows whitespace, so match the collapsed phrase.
+			needles = append(needles, strings.Join(strings.Fields(md[inj.Start:inj.End]), " "))
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range md {
+		if audit.IsInvisible(r) {
+			marker := fmt.Sprintf("⟦U+%04X⟧", r)
+			b.WriteString(marker)
+			needles = append(needles, marker)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), needles
+}
+
+// needlePattern builds a case-insensitive pattern matching any of needles,
+// longest first so overlapping needles highlight the widest span. It returns
+// nil when there is nothing to match.
+func needlePattern(needles []string) *regexp.Regexp {
+	uniq := make(map[string]bool)
+	var quoted []string
+	for _, n := range needles {
+		if n == "" || uniq[n] {
+			continue
+		}
+		uniq[n] = true
+		quoted = append(quoted, regexp.QuoteMeta(n))
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+}
+
+// highlightLines restyles every match of re in rendered terminal output and
+// returns the indices of the lines that matched. Matching runs on the text
+// with ANSI codes stripped, so a highlighted line loses its glamour styling
+// but its matches stay readable regardless of the surrounding colors.
+func highlightLines(rendered string, re *regexp.Regexp, style lipgloss.Style) (string, []int) {
+	if re == nil {
+		return rendered, nil
+	}
+
+	var matched []int
+	lines := strings.Split(rendered, "\n")
+	for i, line := range lines {
+		plain := ansi.Strip(line)
+		locs := re.FindAllStringIndex(plain, -1)
+		if len(locs) == 0 {
+			continue
+		}
+		matched = append(matched, i)
+
+		var b strings.Builder
+		prev := 0
+		for _, loc := range locs {
+			b.WriteString(plain[prev:loc[0]])
+			b.WriteString(style.Render(plain[loc[0]:loc[1]]))
+			prev = loc[1]
+		}
+		b.WriteString(plain[prev:])
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n"), matched
+}
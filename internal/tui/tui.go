@@ -10,18 +10,15 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/smauermann/skillex/internal/audit"
+	"github.com/smauermann/skillex/internal/backend"
+	"github.com/smauermann/skillex/internal/budget"
+	"github.com/smauermann/skillex/internal/config"
 	"github.com/smauermann/skillex/internal/discovery"
-)
-
-// descBudgetLimit is the fallback character budget for all skill descriptions
-// combined in Claude Code's available_skills system prompt section.
-// The actual limit scales dynamically at 2% of the model's context window,
-// with 16,000 chars as the documented fallback. Skills that don't fit are
-// silently excluded with no warning.
-// Source: https://github.com/anthropics/claude-code/issues/13099
-const (
-	descBudgetLimit  = 16_000
-	contentWordLimit = 500
+	"github.com/smauermann/skillex/internal/fingerprint"
+	"github.com/smauermann/skillex/internal/profile"
+	"github.com/smauermann/skillex/internal/search"
+	"github.com/smauermann/skillex/internal/snapshot"
 )
 
 var (
@@ -56,6 +53,10 @@ var (
 	neutralColor   = lipgloss.Color("242") // dim: unclear / no description
 	disabledColor  = lipgloss.Color("238") // very dim: skill is disabled
 
+	// Status line styles, shown in the help bar after an action.
+	statusStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("35")).Background(lipgloss.Color("236"))
+	statusErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Background(lipgloss.Color("236"))
+
 	// analyticsLabelStyle is the left-column label in the analytics panel.
 	analyticsLabelStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("243")).
@@ -69,24 +70,49 @@ type skillItem struct {
 }
 
 func (i skillItem) Title() string       { return i.skill.Name }
-func (i skillItem) Description() string { return i.skill.Plugin }
-func (i skillItem) FilterValue() string { return i.skill.Name + " " + i.skill.Plugin }
+func (i skillItem) Description() string { return skillSource(i.skill) }
+
+// skillSource is the plugin a skill comes from, followed by its category
+// folder when it is nested in one.
+func skillSource(s discovery.Skill) string {
+	if s.Category == "" {
+		return s.Plugin
+	}
+	return s.Plugin + " › " + s.Category
+}
+
+// FilterValue is the skill's directory, a key into the model's skillIndex;
+// the filter itself decides what text to match.
+func (i skillItem) FilterValue() string { return i.skill.Dir() }
 
 // skillDelegate is a custom list.ItemDelegate that renders each skill with an
 // activation-style tag next to the plugin name.
-type skillDelegate struct{}
+type skillDelegate struct {
+	// marked is shared with Model and keyed by skill directory.
+	marked map[string]bool
+	// changes is shared with Model and keyed by skill ID.
+	changes map[string]fingerprint.Status
+}
 
 func (d skillDelegate) Height() int                             { return 2 }
 func (d skillDelegate) Spacing() int                            { return 1 }
 func (d skillDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 func (d skillDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if h, ok := item.(groupHeader); ok {
+		h.render(w, index == m.Index())
+		return
+	}
 	si, ok := item.(skillItem)
 	if !ok {
 		return
 	}
 
 	isSelected := index == m.Index()
+	mark := ""
+	if d.marked[si.skill.Dir()] {
+		mark = markStyle.Render("● ")
+	}
 
 	var prefix string
 	var tStyle, dStyle lipgloss.Style
@@ -103,12 +129,23 @@ func (d skillDelegate) Render(w io.Writer, m list.Model, index int, item list.It
 	if !si.skill.Enabled {
 		dimStyle := lipgloss.NewStyle().Foreground(disabledColor)
 		tag := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("disabled")
-		fmt.Fprintf(w, "%s%s\n  %s %s", prefix, dimStyle.Render(si.skill.Name), dimStyle.Render(si.skill.Plugin), tag)
+		tag += changeBadge(d.changes[si.skill.ID()])
+		fmt.Fprintf(w, "%s%s%s\n  %s %s", prefix, mark, dimStyle.Render(si.skill.Name), dimStyle.Render(skillSource(si.skill)), tag)
 		return
 	}
 
 	tag := activationTag(si.skill.ActivationStyle)
-	fmt.Fprintf(w, "%s%s\n  %s %s", prefix, tStyle.Render(si.skill.Name), dStyle.Render(si.skill.Plugin), tag)
+	if si.skill.Category != "" {
+		tag += " " + conflictTagStyle.Render("not loaded")
+	}
+	if si.skill.Reverted {
+		tag += " " + conflictTagStyle.Render("reverted")
+	}
+	if si.skill.Conflict {
+		tag += " " + conflictTagStyle.Render("conflict")
+	}
+	tag += changeBadge(d.changes[si.skill.ID()])
+	fmt.Fprintf(w, "%s%s%s\n  %s %s", prefix, mark, tStyle.Render(si.skill.Name), dStyle.Render(skillSource(si.skill)), tag)
 }
 
 // activationTag returns a colored word indicating auto-activation reliability.
@@ -135,14 +172,27 @@ func activationAdvice(style discovery.ActivationStyle) string {
 	}
 }
 
+// minAnalyticsHeight is the Skill Analytics panel's usual line count, kept
+// as its minimum height.
+const minAnalyticsHeight = 10
+
 // renderAnalyticsPanel builds the inner content of the Skill Analytics panel.
 func renderAnalyticsPanel(skill discovery.Skill, allSkills []discovery.Skill, width int) string {
 	// Status line: enabled/disabled
 	var statusLine string
-	if skill.Enabled {
+	switch {
+	case skill.Available:
+		statusLine = analyticsLabelStyle.Render("Status") +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("81")).Render("Not installed") +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" (available from "+skill.Marketplace+")")
+	case skill.Category != "":
+		statusLine = analyticsLabelStyle.Render("Status") +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("Not loaded") +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" (nested in "+skill.Category+"/)")
+	case skill.Enabled:
 		statusLine = analyticsLabelStyle.Render("Status") +
 			lipgloss.NewStyle().Foreground(directiveColor).Render("Enabled")
-	} else {
+	default:
 		statusLine = analyticsLabelStyle.Render("Status") +
 			lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("Disabled") +
 			lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" (start a new session to apply)")
@@ -166,15 +216,17 @@ func renderAnalyticsPanel(skill discovery.Skill, allSkills []discovery.Skill, wi
 		lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" · ") +
 		lipgloss.NewStyle().Foreground(adviceColor).Render(advice)
 
+	trustLine := renderTrustLine(skillInjections(skill))
+
 	skillChars := len(skill.Description)
 	descLine := analyticsLabelStyle.Render("Description") +
 		fmt.Sprintf("%d chars", skillChars)
 
 	wordCount := len(strings.Fields(skill.Frontmatter + " " + skill.Content))
 	contentLine := analyticsLabelStyle.Render("Content") +
-		fmt.Sprintf("%d / %d words", wordCount, contentWordLimit)
+		fmt.Sprintf("%d / %d words", wordCount, budget.ContentWordLimit)
 	var contentLegend string
-	if wordCount > contentWordLimit {
+	if wordCount > budget.ContentWordLimit {
 		contentLegend = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(
 			strings.Repeat(" ", 13) + "Verbose: skill is wasting context every conversation")
 	} else {
@@ -182,12 +234,19 @@ func renderAnalyticsPanel(skill discovery.Skill, allSkills []discovery.Skill, wi
 			strings.Repeat(" ", 13) + "Concise: no context pollution")
 	}
 
-	// Budget only counts enabled skills (disabled ones won't load in Claude).
-	totalChars := totalDescChars(allSkills)
-	totalPct := float64(totalChars) / float64(descBudgetLimit)
+	// Budget only counts loaded skills (disabled and nested ones won't load
+	// in Claude).
+	totalChars := budget.Total(allSkills)
+	limit := budget.ResolveLimit()
+	totalPct := float64(totalChars) / float64(limit)
 
-	budgetLine := analyticsLabelStyle.Render("Budget") +
-		fmt.Sprintf("%d / %d chars", totalChars, descBudgetLimit)
+	budgetLabel := "Budget"
+	if skill.Available {
+		// allSkills includes the skills the plugin would bring along.
+		budgetLabel = "If installed"
+	}
+	budgetLine := analyticsLabelStyle.Render(budgetLabel) +
+		fmt.Sprintf("%d / %d chars", totalChars, limit)
 
 	barWidth := width - 13 - 6 // label width - " NNN%" suffix
 	if barWidth < 10 {
@@ -197,31 +256,29 @@ func renderAnalyticsPanel(skill discovery.Skill, allSkills []discovery.Skill, wi
 		progressBar(totalPct, barWidth) +
 		fmt.Sprintf(" %d%%", int(totalPct*100))
 
-	var legend string
 	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	switch {
-	case totalChars >= descBudgetLimit:
-		legend = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(
-			strings.Repeat(" ", 13) + "Exceeded: some skills won't be visible to Claude")
-	case totalChars > descBudgetLimit*8/10:
-		legend = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(
-			strings.Repeat(" ", 13) + "Tight: adding more skills risks silent exclusion")
-	default:
-		legend = dimStyle.Render(
-			strings.Repeat(" ", 13) + "Healthy: all descriptions fit into Claude's context")
+	legendStyle := dimStyle
+	level := budget.Assess(totalChars, limit)
+	switch level {
+	case budget.Exceeded:
+		legendStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	case budget.Tight:
+		legendStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
 	}
+	legend := legendStyle.Render(strings.Repeat(" ", 13) + level.Advice())
 
-	// Show savings from disabled skills.
-	disabledCount, disabledChars := disabledStats(allSkills)
-	var savingsLine string
-	if disabledCount > 0 {
-		savingsLine = dimStyle.Render(
-			fmt.Sprintf("%s%d disabled skill(s) saving %d chars", strings.Repeat(" ", 13), disabledCount, disabledChars))
+	// Savings from disabled skills and nested skills left out share one
+	// line, so the panel keeps its height.
+	lines := []string{statusLine, activationLine, trustLine, descLine, contentLine, contentLegend, budgetLine, barLine, legend}
+	var notes []string
+	if n, chars := budget.Disabled(allSkills); n > 0 {
+		notes = append(notes, fmt.Sprintf("%d disabled skill(s) saving %d chars", n, chars))
 	}
-
-	lines := []string{statusLine, activationLine, descLine, contentLine, contentLegend, budgetLine, barLine, legend}
-	if savingsLine != "" {
-		lines = append(lines, savingsLine)
+	if n := budget.NotLoaded(allSkills); n > 0 {
+		notes = append(notes, fmt.Sprintf("%d nested skill(s) not loaded", n))
+	}
+	if len(notes) > 0 {
+		lines = append(lines, dimStyle.Render(strings.Repeat(" ", 13)+strings.Join(notes, " · ")))
 	}
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
@@ -250,30 +307,6 @@ func renderFrontmatter(raw string) string {
 	return buf.String()
 }
 
-// totalDescChars returns the sum of description lengths across enabled skills.
-// Claude Code silently stops loading skills when this total exceeds the budget.
-// Disabled skills are excluded because Claude never sees them.
-func totalDescChars(skills []discovery.Skill) int {
-	total := 0
-	for _, s := range skills {
-		if s.Enabled {
-			total += len(s.Description)
-		}
-	}
-	return total
-}
-
-// disabledStats returns the count and total description chars of disabled skills.
-func disabledStats(skills []discovery.Skill) (count int, chars int) {
-	for _, s := range skills {
-		if !s.Enabled {
-			count++
-			chars += len(s.Description)
-		}
-	}
-	return
-}
-
 // progressBar renders a colored bar of filled and empty blocks.
 func progressBar(fraction float64, width int) string {
 	if fraction < 0 {
@@ -305,6 +338,10 @@ type Model struct {
 	list          list.Model
 	viewport      viewport.Model
 	skills        []discovery.Skill
+	stateFile     string
+	files         config.Files
+	backend       backend.Backend
+	project       *profile.Project
 	styleOpt      glamour.TermRendererOption
 	renderer      *glamour.TermRenderer
 	rendererWidth int
@@ -312,25 +349,137 @@ type Model struct {
 	height        int
 	ready         bool
 	focusViewport bool
+
+	// tab is the view shown in the preview pane; fileCursor and fileOpen
+	// track navigation inside the Files tab.
+	tab        previewTab
+	fileCursor int
+	fileOpen   bool
+
+	// picker is an open menu shown in place of the skill list, or nil.
+	picker *picker
+
+	// sort is the list order; collapsed holds the plugins whose group is
+	// folded in the plugin order.
+	sort      sortOrder
+	collapsed map[string]bool
+
+	// index backs the structured list filter.
+	index *skillIndex
+
+	// searchIndex backs full-text search; search is the open search, or
+	// nil. searchTerm is highlighted in the SKILL.md preview, searchLines
+	// are the preview lines with hits and searchHit the one last jumped to.
+	searchIndex *search.Index
+	search      *searchMode
+	searchTerm  string
+	searchLines []int
+	searchHit   int
+
+	// available holds the skills of uninstalled plugins in local
+	// marketplace clones; browsing is set while the list shows them.
+	available []discovery.Skill
+	browsing  bool
+
+	// snapshots caches earlier versions of skills for diffs, or is nil
+	// without a state file.
+	snapshots *snapshot.Cache
+
+	// marked holds the directories of skills marked for bulk actions.
+	marked map[string]bool
+	// changes holds the skills that are new or changed since the previous
+	// launch, by ID.
+	changes map[string]fingerprint.Status
+	// report replaces the preview with the result of a bulk action until
+	// the cursor moves; reportTitle names it.
+	report      string
+	reportTitle string
+
+	// status is a one-line result of the last action, shown in the help bar
+	// until the next key press.
+	status    string
+	statusErr bool
 }
 
-// New creates a new TUI model from discovered skills.
-func New(skills []discovery.Skill, styleOpt glamour.TermRendererOption) Model {
-	items := make([]list.Item, len(skills))
-	for i, s := range skills {
-		items[i] = skillItem{skill: s}
-	}
+// previewTab identifies a view in the preview pane, cycled with tab.
+type previewTab int
+
+const (
+	tabSkill previewTab = iota
+	tabFiles
+	tabAudit
+	tabPlugin
+)
 
-	l := list.New(items, skillDelegate{}, 0, 0)
+var previewTabNames = []string{"SKILL.md", "Files", "Audit", "Plugin"}
+
+// New creates a new TUI model from discovered skills. stateFile is the
+// skillex state file holding saved profiles; projectFile is the project's
+// skillex.yaml, used to warn when skills drift from it.
+func New(skills []discovery.Skill, stateFile, projectFile string, styleOpt glamour.TermRendererOption) Model {
+	marked := make(map[string]bool)
+	changes := make(map[string]fingerprint.Status)
+	index := &skillIndex{}
+	l := list.New(nil, skillDelegate{marked: marked, changes: changes}, 0, 0)
+	l.Filter = index.filter
 	l.SetShowTitle(false)
 	l.SetShowHelp(false)
 	l.SetShowStatusBar(false)
 
-	return Model{
-		list:     l,
-		skills:   skills,
-		styleOpt: styleOpt,
+	m := Model{
+		list:      l,
+		skills:    skills,
+		stateFile: stateFile,
+		files:     config.FilesOf(stateFile),
+		backend:   backend.Rename{},
+		styleOpt:  styleOpt,
+		marked:    marked,
+		changes:   changes,
+		index:     index,
+	}
+	m.loadUI()
+	if b, err := backend.Load(stateFile, m.files.ClaudeSettings()); err != nil {
+		m.setError(err)
+	} else {
+		m.backend = b
+		b.Mark(skills)
+	}
+	if projectFile != "" {
+		project, err := profile.LoadProject(projectFile)
+		if err != nil {
+			m.setError(err)
+		}
+		m.project = project
 	}
+	m.list.SetItems(m.items())
+	m.searchIndex = search.Build(skills)
+	m.captureSnapshots()
+	return m
+}
+
+// syncItems refreshes the list after skills changed on disk, keeping the
+// cursor and any active filter.
+func (m Model) syncItems() (Model, tea.Cmd) {
+	cmd := m.list.SetItems(m.items())
+	// Resolving a conflict can reload a skill's content.
+	m.searchIndex = search.Build(m.skills)
+	if m.ready {
+		// The drift banner may have appeared or gone, changing the layout.
+		m = m.resize()
+	}
+	return m.updateViewportContent(), cmd
+}
+
+// setStatus shows an informational message in the help bar.
+func (m *Model) setStatus(format string, args ...any) {
+	m.status = fmt.Sprintf(format, args...)
+	m.statusErr = false
+}
+
+// setError shows an error in the help bar.
+func (m *Model) setError(err error) {
+	m.status = "Error: " + err.Error()
+	m.statusErr = true
 }
 
 func (m Model) Init() tea.Cmd {
@@ -344,13 +493,64 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		// Don't intercept keys when filtering
 		if m.list.FilterState() == list.Filtering {
-			var cmd tea.Cmd
-			m.list, cmd = m.list.Update(msg)
-			return m, cmd
+			return m.updateList(msg)
+		}
+		m.status = ""
+		if m.picker != nil {
+			return m.updatePicker(msg.String())
+		}
+		if m.search != nil {
+			return m.updateSearch(msg)
 		}
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
+		case "P":
+			return m.openProfilePicker(), nil
+		case "S":
+			if m.project != nil {
+				return m.syncProject()
+			}
+		case "A":
+			return m.toggleAvailable()
+		case "v":
+			if !m.focusViewport && !m.installedOnly() {
+				return m.markSelected(), nil
+			}
+		case "V":
+			if !m.focusViewport && !m.installedOnly() {
+				return m.markVisible(), nil
+			}
+		case "o":
+			return m.openSortPicker(), nil
+		case "?":
+			return m.openSearch()
+		case "d":
+			// In the preview, d pages down.
+			if !m.focusViewport {
+				if m.installedOnly() {
+					return m, nil
+				}
+				return m.openDiff(), nil
+			}
+		case "n", "N":
+			if m.searchTerm != "" && m.tab == tabSkill && m.report == "" {
+				delta := 1
+				if msg.String() == "N" {
+					delta = -1
+				}
+				m.focusViewport = true
+				return m.jumpSearchHit(delta), nil
+			}
+		case "x":
+			if m.installedOnly() {
+				return m, nil
+			}
+			return m.openBulkPicker(), nil
+		case "R":
+			if len(m.revertedSkills()) > 0 {
+				return m.reapply()
+			}
 		case "l":
 			if !m.focusViewport {
 				m.focusViewport = true
@@ -359,56 +559,52 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "h":
 			if m.focusViewport {
 				m.focusViewport = false
+				if m.fileOpen {
+					m.fileOpen = false
+					m = m.updateViewportContent()
+				}
 				return m, nil
 			}
+		case "tab":
+			m.report = ""
+			m.tab = (m.tab + 1) % previewTab(len(previewTabNames))
+			m.fileCursor, m.fileOpen = 0, false
+			m = m.updateViewportContent()
+			return m, nil
+		case "j", "down", "k", "up", "enter", "esc":
+			if m.focusViewport && m.tab == tabFiles {
+				if next, handled := m.updateFiles(msg.String()); handled {
+					return next, nil
+				}
+			}
+			if msg.String() == "esc" && m.focusViewport && m.searchTerm != "" {
+				return m.clearSearch(), nil
+			}
 		case " ":
 			if !m.focusViewport {
-				if si, ok := m.list.SelectedItem().(skillItem); ok {
-					for i := range m.skills {
-						if m.skills[i].FilePath == si.skill.FilePath {
-							if err := discovery.ToggleSkill(&m.skills[i]); err == nil {
-								m.list.SetItem(m.list.Index(), skillItem{skill: m.skills[i]})
-								m = m.updateViewportContent()
-							}
-							break
-						}
-					}
+				if next, ok := m.toggleGroup(); ok {
+					return next.syncItems()
 				}
-				return m, nil
+				if m.installedOnly() {
+					return m, nil
+				}
+				return m.toggleSelected()
+			}
+		case "u", "ctrl+r":
+			// In the preview, u pages up; undo only acts on the list so
+			// scrolling never renames files.
+			if !m.focusViewport {
+				if msg.String() == "u" {
+					return m.undo()
+				}
+				return m.redo()
 			}
 		}
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-
-		contentHeight := msg.Height - 1 // reserve 1 row for help bar
-		listWidth := msg.Width / 3
-		viewportWidth := msg.Width - listWidth
-
-		// Content width = total panel width - borders(2) - padding(2)
-		listContentWidth := listWidth - 4
-		vpContentWidth := viewportWidth - 4
-
-		// Analytics panel: 10 inner rows + top border(1) + bottom border(1) = 12 total rows
-		analyticsHeight := 12
-
-		// List panel: full content height minus borders
-		listInnerHeight := contentHeight - 2
-
-		// Viewport panel: remaining height after analytics panel and its borders
-		vpInnerHeight := contentHeight - analyticsHeight - 2
-
-		m.list.SetSize(listContentWidth, listInnerHeight)
-
-		if !m.ready {
-			m.viewport = viewport.New(vpContentWidth, vpInnerHeight)
-			m.ready = true
-			m = m.updateViewportContent()
-		} else {
-			m.viewport.Width = vpContentWidth
-			m.viewport.Height = vpInnerHeight
-		}
+		m = m.resize()
 	}
 
 	// Route key events to the focused pane only.
@@ -420,10 +616,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		prevIndex := m.list.Index()
 
 		var cmd tea.Cmd
-		m.list, cmd = m.list.Update(msg)
+		m, cmd = m.updateList(msg)
 		cmds = append(cmds, cmd)
 
 		if m.list.Index() != prevIndex {
+			m.fileCursor, m.fileOpen = 0, false
+			m.report = ""
 			m = m.updateViewportContent()
 		}
 	}
@@ -440,56 +638,257 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// resize lays out the list and viewport for the current window size.
+func (m Model) resize() Model {
+	contentHeight := m.height - 1 - m.bannerHeight() // reserve 1 row for help bar
+	listWidth := m.width / 3
+	viewportWidth := m.width - listWidth
+
+	// Content width = total panel width - borders(2) - padding(2)
+	listContentWidth := listWidth - 4
+	vpContentWidth := viewportWidth - 4
+
+	// List panel: full content height minus borders
+	listInnerHeight := contentHeight - 2
+
+	vpInnerHeight := m.previewHeight()
+
+	m.list.SetSize(listContentWidth, listInnerHeight)
+
+	if !m.ready {
+		m.viewport = viewport.New(vpContentWidth, vpInnerHeight)
+		m.ready = true
+		m = m.updateViewportContent()
+	} else {
+		m.viewport.Width = vpContentWidth
+		m.viewport.Height = vpInnerHeight
+	}
+	return m
+}
+
+// analyticsContent renders the Skill Analytics panel's content for the
+// selected skill, wrapped to width.
+func (m Model) analyticsContent(width int) string {
+	var content string
+	if selected, ok := m.list.SelectedItem().(skillItem); ok {
+		content = renderAnalyticsPanel(selected.skill, m.budgetSkills(selected.skill), width)
+	} else {
+		content = lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render("No skill selected.")
+	}
+	return lipgloss.NewStyle().Width(width).Render(content)
+}
+
+// analyticsRows is the inner height of the Skill Analytics panel showing
+// content: every rendered line, but at least minAnalyticsHeight rows so the
+// preview below keeps its place while moving through the list.
+func analyticsRows(content string) int {
+	return max(lipgloss.Height(content), minAnalyticsHeight)
+}
+
+// previewHeight is the preview viewport's height: what the Skill Analytics
+// panel above, sized to the selected skill, and the borders leave.
+func (m Model) previewHeight() int {
+	contentHeight := m.height - 1 - m.bannerHeight() // reserve 1 row for help bar
+	vpContentWidth := m.width - m.width/3 - 4
+	analytics := analyticsRows(m.analyticsContent(vpContentWidth)) + 2 // + borders
+	return contentHeight - analytics - 2
+}
+
 func (m Model) updateViewportContent() Model {
+	if m.ready {
+		// The selected skill sets the analytics panel's height.
+		m.viewport.Height = m.previewHeight()
+	}
+	if m.report != "" {
+		m.viewport.SetContent(m.report)
+		return m
+	}
+
 	selected, ok := m.list.SelectedItem().(skillItem)
 	if !ok {
 		m.viewport.SetContent("No skill selected.")
 		return m
 	}
 
+	if m.tab == tabAudit {
+		m.viewport.SetContent(renderAuditFindings(audit.Scan(selected.skill), m.viewport.Width))
+		m.viewport.GotoTop()
+		return m
+	}
+
+	if m.tab == tabPlugin {
+		m.viewport.SetContent(renderPluginDetails(selected.skill, m.listSkills(), m.viewport.Width))
+		m.viewport.GotoTop()
+		return m
+	}
+
+	if m.tab == tabFiles && !m.fileOpen {
+		content, cursorLine := renderFileTree(selected.skill, m.fileCursor)
+		m.viewport.SetContent(content)
+		// Keep the cursor on screen without jumping back to the top.
+		switch {
+		case cursorLine < m.viewport.YOffset:
+			m.viewport.SetYOffset(cursorLine)
+		case cursorLine >= m.viewport.YOffset+m.viewport.Height:
+			m.viewport.SetYOffset(cursorLine - m.viewport.Height + 1)
+		}
+		return m
+	}
+
+	var md string
+	var needles []string
+	if m.tab == tabFiles {
+		md = filePreviewMarkdown(selected.skill, selected.skill.Files[m.fileCursor])
+	} else {
+		md, needles = revealInjections(skillMarkdown(selected.skill))
+	}
+
+	rendered, err := m.renderMarkdown(md)
+	if err != nil {
+		m.viewport.SetContent(fmt.Sprintf("Render error: %v", err))
+		return m
+	}
+	m.searchLines, m.searchHit = nil, -1
+	if m.tab == tabSkill && m.searchTerm != "" {
+		// Search hits first, so injection highlighting wins where both match.
+		rendered, m.searchLines = highlightLines(rendered, needlePattern(search.Tokenize(m.searchTerm)), searchHighlightStyle)
+	}
+	rendered, _ = highlightLines(rendered, needlePattern(needles), injectionHighlightStyle)
+
+	m.viewport.SetContent(rendered)
+	m.viewport.GotoTop()
+	return m
+}
+
+// updatePicker handles keys while a picker is open.
+func (m Model) updatePicker(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.picker = nil
+	case "j", "down":
+		m.picker.move(1)
+	case "k", "up":
+		m.picker.move(-1)
+	case "enter":
+		opt, ok := m.picker.selected()
+		kind, target := m.picker.kind, m.picker.target
+		m.picker = nil
+		if !ok {
+			return m, nil
+		}
+		switch kind {
+		case pickProfile:
+			m = m.applyProfile(opt.value)
+		case pickConflict:
+			m = m.resolveConflict(target, opt.value)
+		case pickBulk:
+			m = m.runBulk(opt.value)
+		case pickSort:
+			m = m.setSort(opt.value)
+		case pickDiff:
+			m = m.diffWith(target, opt.value)
+		}
+		return m.syncItems()
+	}
+	return m, nil
+}
+
+// updateFiles handles navigation keys inside the Files tab. It reports
+// whether the key was consumed; unconsumed keys scroll the viewport.
+func (m Model) updateFiles(key string) (Model, bool) {
+	selected, ok := m.list.SelectedItem().(skillItem)
+	if !ok || len(selected.skill.Files) == 0 {
+		return m, false
+	}
+
+	if m.fileOpen {
+		if key != "esc" {
+			return m, false
+		}
+		m.fileOpen = false
+		return m.updateViewportContent(), true
+	}
+
+	switch key {
+	case "j", "down":
+		if m.fileCursor < len(selected.skill.Files)-1 {
+			m.fileCursor++
+		}
+	case "k", "up":
+		if m.fileCursor > 0 {
+			m.fileCursor--
+		}
+	case "enter":
+		m.fileOpen = true
+	default:
+		return m, false
+	}
+	return m.updateViewportContent(), true
+}
+
+// renderMarkdown renders md with glamour at the current viewport width,
+// recreating the renderer only when the width changes.
+func (m *Model) renderMarkdown(md string) (string, error) {
 	width := m.viewport.Width - 2
 	if width < 20 {
 		width = 20
 	}
 
-	// Recreate renderer only when width changes.
 	if m.renderer == nil || width != m.rendererWidth {
 		r, err := glamour.NewTermRenderer(
 			m.styleOpt,
 			glamour.WithWordWrap(width),
 		)
 		if err != nil {
-			m.viewport.SetContent(fmt.Sprintf("Render error: %v", err))
-			return m
+			return "", err
 		}
 		m.renderer = r
 		m.rendererWidth = width
 	}
+	return m.renderer.Render(md)
+}
 
-	// Build the markdown content: frontmatter + separator + body.
+// skillMarkdown builds the preview markdown for a skill: frontmatter +
+// separator + body.
+func skillMarkdown(skill discovery.Skill) string {
 	var md strings.Builder
-	fm := renderFrontmatter(selected.skill.Frontmatter)
+	fm := renderFrontmatter(skill.Frontmatter)
 	if fm != "" {
 		md.WriteString("---\n\n")
 		md.WriteString(fm)
 		md.WriteString("---\n\n")
 	}
-	md.WriteString(selected.skill.Content)
-
-	rendered, err := m.renderer.Render(md.String())
-	if err != nil {
-		m.viewport.SetContent(fmt.Sprintf("Render error: %v", err))
-		return m
-	}
-
-	m.viewport.SetContent(rendered)
-	m.viewport.GotoTop()
-	return m
+	md.WriteString(skill.Content)
+	return md.String()
 }
 
 // renderPanel draws a bordered panel with the title embedded in the top border line.
 // totalWidth is the full desired width of the panel including borders.
 func renderPanel(title string, content string, totalWidth, height int, borderColor lipgloss.Color) string {
+	titleStyled := lipgloss.NewStyle().Bold(true).Foreground(borderColor).Render(title)
+	return renderPanelWithTitle(titleStyled, content, totalWidth, height, borderColor)
+}
+
+// renderTabbedPanel draws a bordered panel whose top border lists tabs, with
+// the active one highlighted.
+func renderTabbedPanel(tabs []string, active int, content string, totalWidth, height int, borderColor lipgloss.Color) string {
+	parts := make([]string, len(tabs))
+	for i, t := range tabs {
+		if i == active {
+			parts[i] = lipgloss.NewStyle().Bold(true).Foreground(borderColor).Render(t)
+		} else {
+			parts[i] = lipgloss.NewStyle().Foreground(blurredBorderColor).Render(t)
+		}
+	}
+	sep := lipgloss.NewStyle().Foreground(borderColor).Render(" │ ")
+	return renderPanelWithTitle(strings.Join(parts, sep), content, totalWidth, height, borderColor)
+}
+
+// renderPanelWithTitle draws a bordered panel around content with an already
+// styled title embedded in the top border line.
+func renderPanelWithTitle(titleStyled string, content string, totalWidth, height int, borderColor lipgloss.Color) string {
 	border := lipgloss.RoundedBorder()
 
 	// lipgloss .Width() includes padding but excludes borders.
@@ -501,7 +900,6 @@ func renderPanel(title string, content string, totalWidth, height int, borderCol
 		Render(content)
 
 	// Build the top border line to match body width exactly.
-	titleStyled := lipgloss.NewStyle().Bold(true).Foreground(borderColor).Render(title)
 	titleWidth := lipgloss.Width(titleStyled)
 	colorStyle := lipgloss.NewStyle().Foreground(borderColor)
 
@@ -522,11 +920,36 @@ func renderPanel(title string, content string, totalWidth, height int, borderCol
 func (m Model) helpBar() string {
 	key := helpKeyStyle.Render
 
+	if m.status != "" {
+		style := statusStyle
+		if m.statusErr {
+			style = statusErrorStyle
+		}
+		return helpBarStyle.Width(m.width).Render(style.Render(m.status))
+	}
+
 	var content string
-	if m.focusViewport {
-		content = key("j/k") + " scroll  " + key("h") + " back to list  " + key("/") + " filter  " + key("q") + " quit"
-	} else {
-		content = key("j/k") + " navigate  " + key("space") + " toggle  " + key("l") + " read preview  " + key("/") + " filter  " + key("q") + " quit"
+	switch {
+	case m.filterError() != nil:
+		content = statusErrorStyle.Render("Query: " + m.filterError().Error())
+	case m.picker != nil:
+		content = key("j/k") + " navigate  " + key("enter") + " apply  " + key("esc") + " close"
+	case m.search != nil:
+		content = key("type") + " to search  " + key("↑/↓") + " select  " + key("enter") + " open  " + key("esc") + " close"
+	case m.focusViewport && m.tab == tabFiles && m.fileOpen:
+		content = key("j/k") + " scroll  " + key("esc") + " back to files  " + key("h") + " back to list  " + key("q") + " quit"
+	case m.focusViewport && m.tab == tabFiles:
+		content = key("j/k") + " select file  " + key("enter") + " preview  " + key("tab") + " switch view  " + key("h") + " back to list  " + key("q") + " quit"
+	case m.focusViewport && len(m.searchLines) > 0 && m.tab == tabSkill:
+		content = key("j/k") + " scroll  " + key("n/N") + " next/prev match  " + key("esc") + " clear highlight  " + key("h") + " back to list  " + key("?") + " search  " + key("q") + " quit"
+	case m.focusViewport:
+		content = key("j/k") + " scroll  " + key("tab") + " switch view  " + key("h") + " back to list  " + key("/") + " filter  " + key("q") + " quit"
+	case m.browsing:
+		content = key("j/k") + " navigate  " + key("l") + " read preview  " + key("tab") + " switch view  " + key("A") + " installed skills  " + key("o") + " sort  " + key("/") + " filter  " + key("q") + " quit"
+	case len(m.marked) > 0:
+		content = key("j/k") + " navigate  " + key("v") + " mark  " + key("V") + " mark filtered  " + key("x") + " bulk actions  " + key("d") + " diff two  " + key("/") + " filter  " + key("q") + " quit"
+	default:
+		content = key("j/k") + " navigate  " + key("space") + " toggle  " + key("v") + " mark  " + key("l") + " read preview  " + key("tab") + " switch view  " + key("P") + " profiles  " + key("o") + " sort  " + key("u") + " undo  " + key("/") + " filter  " + key("?") + " search  " + key("q") + " quit"
 	}
 
 	return helpBarStyle.Width(m.width).Render(content)
@@ -537,12 +960,13 @@ func (m Model) View() string {
 		return "Loading..."
 	}
 
-	contentHeight := m.height - 1 // reserve for help bar
+	contentHeight := m.height - 1 - m.bannerHeight() // reserve for help bar
 	listWidth := m.width / 3
 	viewportWidth := m.width - listWidth
 
-	// Analytics panel: 10 inner rows
-	analyticsInnerHeight := 10
+	// Analytics panel: the selected skill's lines
+	analyticsContent := m.analyticsContent(viewportWidth - 4)
+	analyticsInnerHeight := analyticsRows(analyticsContent)
 	analyticsHeight := analyticsInnerHeight + 2 // + borders
 
 	// List panel: full content height - borders
@@ -559,23 +983,33 @@ func (m Model) View() string {
 		vpBorderColor = focusedBorderColor
 	}
 
-	// Left pane: Skills list
-	leftPane := renderPanel("Skills", m.list.View(), listWidth, listPanelHeight, listBorderColor)
+	// Left pane: Skills list, or the open picker
+	var leftPane string
+	switch {
+	case m.picker != nil:
+		leftPane = renderPanel(m.picker.title, m.picker.view(), listWidth, listPanelHeight, focusedBorderColor)
+	case m.search != nil:
+		leftPane = renderPanel("Search", m.search.view(m, listWidth-4, listPanelHeight), listWidth, listPanelHeight, focusedBorderColor)
+	default:
+		leftPane = renderPanel(m.listTitle(), m.list.View(), listWidth, listPanelHeight, listBorderColor)
+	}
 
 	// Right pane top: Skill Analytics
-	var analyticsContent string
-	if selected, ok := m.list.SelectedItem().(skillItem); ok {
-		analyticsContent = renderAnalyticsPanel(selected.skill, m.skills, viewportWidth-4)
-	} else {
-		analyticsContent = lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render("No skill selected.")
-	}
 	analyticsPane := renderPanel("Skill Analytics", analyticsContent, viewportWidth, analyticsInnerHeight, vpBorderColor)
 
-	// Right pane bottom: SKILL.md viewport
-	vpPane := renderPanel("SKILL.md", m.viewport.View(), viewportWidth, vpPanelHeight, vpBorderColor)
+	// Right pane bottom: SKILL.md / Files viewport
+	var vpPane string
+	if m.report != "" {
+		vpPane = renderPanel(m.reportTitle, m.viewport.View(), viewportWidth, vpPanelHeight, vpBorderColor)
+	} else {
+		vpPane = renderTabbedPanel(previewTabNames, int(m.tab), m.viewport.View(), viewportWidth, vpPanelHeight, vpBorderColor)
+	}
 
 	rightColumn := lipgloss.JoinVertical(lipgloss.Left, analyticsPane, vpPane)
 	panes := lipgloss.JoinHorizontal(lipgloss.Top, leftPane, rightColumn)
 
+	if banner := m.banner(); banner != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, banner, panes, m.helpBar())
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, panes, m.helpBar())
 }
@@ -3,20 +3,15 @@ package main
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/muesli/termenv"
-	"github.com/smauermann/skillex/internal/discovery"
+	"github.com/smauermann/skillex/internal/cli"
+	"github.com/smauermann/skillex/internal/config"
 	"github.com/smauermann/skillex/internal/tui"
 )
 
-func isDir(path string) bool {
-	info, err := os.Stat(path)
-	return err == nil && info.IsDir()
-}
-
 func main() {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -24,17 +19,30 @@ func main() {
 		os.Exit(1)
 	}
 
-	pluginsFile := filepath.Join(homeDir, ".claude", "plugins", "installed_plugins.json")
-
-	// Collect local skill directories that exist: home-level and project-level
-	var localDirs []discovery.LocalSkillsDir
-	if dir := filepath.Join(homeDir, ".claude", "skills"); isDir(dir) {
-		localDirs = append(localDirs, discovery.LocalSkillsDir{Path: dir, Name: "local"})
+	opts, args, err := config.ParseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(cli.ExitError)
 	}
-	if wd, err := os.Getwd(); err == nil && wd != homeDir {
-		if dir := filepath.Join(wd, ".claude", "skills"); isDir(dir) {
-			localDirs = append(localDirs, discovery.LocalSkillsDir{Path: dir, Name: filepath.Base(wd)})
-		}
+	wd, _ := os.Getwd()
+	cfg, err := config.Load(opts, homeDir, wd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(cli.ExitError)
+	}
+
+	// Any argument selects a non-interactive subcommand.
+	if len(args) > 0 {
+		os.Exit(cli.Run(args, cli.Env{
+			PluginsFile: cfg.PluginsFile,
+			LocalDirs:   cfg.LocalDirs,
+			StateFile:   cfg.StateFile,
+			ProjectFile: cfg.ProjectFile,
+			ProjectDir:  cfg.ProjectDir,
+			Exclude:     cfg.Exclude,
+			Stdout:      os.Stdout,
+			Stderr:      os.Stderr,
+		}))
 	}
 
 	// Detect terminal style BEFORE bubbletea takes over stdin.
@@ -45,7 +53,8 @@ func main() {
 		styleOpt = glamour.WithStylePath("light")
 	}
 
-	p := tea.NewProgram(tui.NewSplash(pluginsFile, localDirs, styleOpt), tea.WithAltScreen())
+	splash := tui.NewSplash(cfg.PluginsFile, cfg.LocalDirs, cfg.Exclude, cfg.StateFile, cfg.ProjectFile, styleOpt)
+	p := tea.NewProgram(splash, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)